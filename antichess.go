@@ -0,0 +1,64 @@
+package chess
+
+// the pieces that a pawn may promote to in Antichess
+var antichessPromotions = [...]PieceType{PieceQueen, PieceRook, PieceBishop, PieceKnight, PieceKing}
+
+// Antichess is the Variant where a player wins by losing all of their
+// pieces, or by having no legal moves. Captures are compulsory, the king
+// is an ordinary piece which may be captured, pawns may also promote to
+// kings, and there is no check or castling.
+type Antichess struct {
+	Standard
+}
+
+// Name returns "Antichess".
+func (Antichess) Name() string {
+	return "Antichess"
+}
+
+// Init sets up the classic chess layout, without any castles.
+func (Antichess) Init(g *Game) {
+	g.InitClassic()
+	g.Castles = castlingRights{WhiteFiles: ClassicCastleFiles, BlackFiles: ClassicCastleFiles}
+}
+
+// Moves appends all of c's legal moves to moves, which
+// are only captures if c is able to capture anything.
+func (Antichess) Moves(g *Game, c Color, moves []Move) []Move {
+	var candidates []move
+	for own := g.colors[colorIndex(c)]; own != 0; {
+		candidates = g.pseudoMovesFrom(own.pop(), antichessPromotions[:], candidates)
+	}
+
+	// if any move captures, only keep the captures
+	enemy := g.colors[colorIndex(c.Other())]
+	captures := candidates[:0]
+	for _, mv := range candidates {
+		if mv.enPassant || enemy&squareBit(mv.to) != 0 {
+			captures = append(captures, mv)
+		}
+	}
+	if len(captures) > 0 {
+		candidates = captures
+	}
+
+	for _, mv := range candidates {
+		moves = append(moves, g.moveOf(mv))
+	}
+	return moves
+}
+
+// InCheck returns false, as there is no check in Antichess.
+func (Antichess) InCheck(g *Game, c Color) bool {
+	return false
+}
+
+// Completion ends the game once the player to move has no legal moves,
+// including when they have no pieces left, in which case that player wins.
+// The game is not drawn by insufficient material.
+func (Antichess) Completion(g *Game) CompletionState {
+	if !g.canMove(g.Turn()) {
+		return CompletionState{Done: true, Winner: g.Turn(), Reason: TerminationNoMoves}
+	}
+	return g.automaticDraw()
+}
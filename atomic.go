@@ -0,0 +1,109 @@
+package chess
+
+// Atomic is the Variant where every capture causes an explosion, which
+// removes the capturing piece along with every piece other than pawns
+// next to the captured one. A player wins by exploding the other's king.
+// Since a king cannot capture without exploding, kings may stand next
+// to each other, and a king next to the other king is never in check.
+type Atomic struct {
+	Standard
+}
+
+// Name returns "Atomic".
+func (Atomic) Name() string {
+	return "Atomic"
+}
+
+// Seeing returns the spaces that p can see, which
+// for kings does not include the other player's pieces.
+func (Atomic) Seeing(g *Game, p Piece) Bitboard {
+	seeing := Standard{}.Seeing(g, p)
+	if p.Type == PieceKing {
+		seeing &^= g.colors[colorIndex(p.Color.Other())]
+	}
+	return seeing
+}
+
+// Moves appends all of c's legal moves to moves. A move is legal if
+// it does not explode c's own king, and either it explodes the other
+// king or does not leave c in check.
+func (Atomic) Moves(g *Game, c Color, moves []Move) []Move {
+	if g.Bitboard(c, PieceKing) == 0 || g.Bitboard(c.Other(), PieceKing) == 0 {
+		return moves
+	}
+
+	own := g.colors[colorIndex(c)]
+	enemy := g.colors[colorIndex(c.Other())]
+
+	var candidates []move
+	for pieces := own; pieces != 0; {
+		from := pieces.pop()
+		if g.mailbox[from] == PieceKing {
+			candidates = g.castleMoves(c, from, candidates)
+		}
+		candidates = g.pseudoMovesFrom(from, promotions[:], candidates)
+	}
+
+	for _, mv := range candidates {
+		if g.mailbox[mv.from] == PieceKing && enemy&squareBit(mv.to) != 0 {
+			continue
+		}
+
+		u := g.makeMove(mv)
+		legal := g.Bitboard(c, PieceKing) != 0 &&
+			(g.Bitboard(c.Other(), PieceKing) == 0 || !atomicInCheck(g, c))
+		g.unmakeMove(u)
+
+		if legal {
+			moves = append(moves, g.moveOf(mv))
+		}
+	}
+	return moves
+}
+
+// AfterMove explodes the pieces around the space captured by m.
+func (Atomic) AfterMove(g *Game, m Move, captured PieceType) {
+	if captured == PieceNone {
+		return
+	}
+
+	to := squareOf(m.To)
+	exploded := kingAttacks[to]&g.occupied()&^g.types[PiecePawn] | squareBit(to)
+	for exploded != 0 {
+		sq := exploded.pop()
+		g.remove(sq)
+		g.updateCastles(sq)
+	}
+}
+
+// InCheck returns if c's king is attacked, and is not next to the other king.
+func (Atomic) InCheck(g *Game, c Color) bool {
+	return atomicInCheck(g, c)
+}
+
+func atomicInCheck(g *Game, c Color) bool {
+	king := g.Bitboard(c, PieceKing)
+	if king == 0 || kingAttacks[king.lowest()]&g.Bitboard(c.Other(), PieceKing) != 0 {
+		return false
+	}
+	return g.inCheck(c)
+}
+
+// Completion ends the game once a king has exploded, or by the standard
+// rules. Since a single piece can explode a king, the game is only drawn
+// by insufficient material if both players only have a king.
+func (Atomic) Completion(g *Game) CompletionState {
+	switch {
+	case g.Bitboard(White, PieceKing) == 0:
+		return CompletionState{Done: true, Winner: Black, Reason: TerminationExplosion}
+	case g.Bitboard(Black, PieceKing) == 0:
+		return CompletionState{Done: true, Winner: White, Reason: TerminationExplosion}
+	case g.InCheckmate(g.Turn()):
+		return CompletionState{Done: true, Winner: g.Turn().Other(), Reason: TerminationCheckmate}
+	case g.InStalemate(g.Turn()):
+		return CompletionState{Done: true, Draw: true, Reason: TerminationStalemate}
+	case g.InsufficientMaterial() == MaterialKingVsKing:
+		return CompletionState{Done: true, Draw: true, Reason: TerminationInsufficientMaterial}
+	}
+	return g.automaticDraw()
+}
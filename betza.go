@@ -0,0 +1,275 @@
+package chess
+
+import (
+	"errors"
+	"strconv"
+)
+
+// betzaMove is one way that a fairy piece moves, parsed from Betza notation.
+type betzaMove struct {
+	// the directions of the move, as seen by white,
+	// so that forward is towards the other player
+	directions []offset
+
+	// the most times that the piece may step in a direction, or 0 if unlimited
+	limit int
+
+	// if the move may go to an empty space, or capture a piece
+	move, capture bool
+
+	// 'p' if the move must hop over a piece, and may land anywhere
+	// beyond it, 'g' if it must land right after the piece, or 0
+	hop byte
+}
+
+// the atoms of Betza notation, by the offset of a single step
+var betzaAtoms = map[byte]offset{
+	'W': {file: 1, rank: 0},
+	'F': {file: 1, rank: 1},
+	'D': {file: 2, rank: 0},
+	'N': {file: 2, rank: 1},
+	'A': {file: 2, rank: 2},
+	'H': {file: 3, rank: 0},
+	'C': {file: 3, rank: 1},
+	'Z': {file: 3, rank: 2},
+	'G': {file: 3, rank: 3},
+}
+
+// the shorthands for combinations of atoms, and if they ride
+var betzaCompounds = map[byte]struct {
+	atoms string
+	rider bool
+}{
+	'R': {atoms: "W", rider: true},
+	'B': {atoms: "F", rider: true},
+	'Q': {atoms: "WF", rider: true},
+	'K': {atoms: "WF", rider: false},
+}
+
+// parseBetza parses the moves of a piece in Betza notation. Each move is
+// an atom, such as N for the knight's leap, which may be preceded by
+// modifiers and followed by a range. Supported modifiers are directions
+// (f, b, l, r, v and s, where f and b followed by l or r name a single
+// diagonal), m for moving without capturing, c for only capturing, and p
+// and g for hopping over a piece. An atom is repeated, such as WW, or
+// followed by 0 to ride any distance, or by a number to ride at most that
+// far. R, B, Q and K are shorthand for WW, FF, WWFF and WF.
+func parseBetza(betza string) ([]betzaMove, error) {
+	var moves []betzaMove
+	for i := 0; i < len(betza); {
+		var dirs string
+		var move, capture bool
+		var hop byte
+		for ; i < len(betza) && betza[i] >= 'a' && betza[i] <= 'z'; i++ {
+			switch char := betza[i]; char {
+			case 'f', 'b', 'l', 'r', 'v', 's':
+				dirs += string(char)
+			case 'm':
+				move = true
+			case 'c':
+				capture = true
+			case 'p', 'g':
+				hop = char
+			default:
+				return nil, errors.New("unsupported modifier " + string(char))
+			}
+		}
+		if !move && !capture {
+			move, capture = true, true
+		}
+		if i == len(betza) {
+			return nil, errors.New("expected an atom after the modifiers")
+		}
+
+		atom := betza[i]
+		i++
+
+		atoms, rider := string(atom), false
+		if compound, ok := betzaCompounds[atom]; ok {
+			atoms, rider = compound.atoms, compound.rider
+		} else if _, ok := betzaAtoms[atom]; !ok {
+			return nil, errors.New("unknown atom " + string(atom))
+		} else if i < len(betza) && betza[i] == atom {
+			rider = true
+			i++
+		}
+
+		limit := 1
+		if rider {
+			limit = 0
+		}
+		start := i
+		for i < len(betza) && betza[i] >= '0' && betza[i] <= '9' {
+			i++
+		}
+		if start < i {
+			limit, _ = strconv.Atoi(betza[start:i])
+		}
+
+		for _, char := range []byte(atoms) {
+			directions := betzaDirections(betzaAtoms[char], dirs)
+			if len(directions) == 0 {
+				return nil, errors.New("no directions of " + string(atom) + " match " + dirs)
+			}
+			moves = append(moves, betzaMove{
+				directions: directions,
+				limit:      limit,
+				move:       move,
+				capture:    capture,
+				hop:        hop,
+			})
+		}
+	}
+	if len(moves) == 0 {
+		return nil, errors.New("expected at least one move")
+	}
+	return moves, nil
+}
+
+// betzaDirections returns each direction of atom that matches the
+// direction modifiers dirs, or all of them if dirs is empty.
+func betzaDirections(atom offset, dirs string) []offset {
+	var directions []offset
+	for _, swapped := range [...]offset{atom, {file: atom.rank, rank: atom.file}} {
+		for _, fileSign := range [...]int{1, -1} {
+			for _, rankSign := range [...]int{1, -1} {
+				off := offset{file: swapped.file * fileSign, rank: swapped.rank * rankSign}
+				if !containsOffset(directions, off) && matchesDirections(off, dirs) {
+					directions = append(directions, off)
+				}
+			}
+		}
+	}
+	return directions
+}
+
+// matchesDirections returns if off matches the direction modifiers dirs.
+// f and b followed by l or r must match both, and the rest match on their own.
+func matchesDirections(off offset, dirs string) bool {
+	if dirs == "" {
+		return true
+	}
+
+	matches := func(dir byte) bool {
+		switch dir {
+		case 'f':
+			return off.rank > 0
+		case 'b':
+			return off.rank < 0
+		case 'l':
+			return off.file < 0
+		case 'r':
+			return off.file > 0
+		case 'v':
+			return abs(off.rank) > abs(off.file)
+		case 's':
+			return abs(off.file) > abs(off.rank)
+		}
+		return false
+	}
+	for i := 0; i < len(dirs); i++ {
+		if (dirs[i] == 'f' || dirs[i] == 'b') && i+1 < len(dirs) && (dirs[i+1] == 'l' || dirs[i+1] == 'r') {
+			if matches(dirs[i]) && matches(dirs[i+1]) {
+				return true
+			}
+			i++
+			continue
+		}
+		if matches(dirs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOffset(offsets []offset, off offset) bool {
+	for _, each := range offsets {
+		if each == off {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// turnsOf returns the number of quarter turns that c's pieces are turned
+// by, as black sees the board turned around.
+func turnsOf(c Color) int {
+	if c == Black {
+		return 2
+	}
+	return 0
+}
+
+// turned returns off turned clockwise by the given number of quarter turns.
+func (off offset) turned(turns int) offset {
+	for i := 0; i < turns%4; i++ {
+		off = offset{file: off.rank, rank: -off.file}
+	}
+	return off
+}
+
+// walkBetza calls visit with each space that a piece on from may reach
+// with moves, and if it may move there without capturing or capture
+// there. onBoard and occupied describe the board, and the directions of
+// the moves are turned clockwise by the given number of quarter turns,
+// so that forward is always towards the other side. It stops once visit
+// returns false, in which case it also returns false.
+func walkBetza(moves []betzaMove, from Space, turns int, onBoard, occupied func(Space) bool, visit func(to Space, quiet, capture bool) bool) bool {
+	for _, bm := range moves {
+		for _, dir := range bm.directions {
+			dir = dir.turned(turns)
+
+			screened := bm.hop == 0
+			to := from
+			for step := 0; bm.limit == 0 || step < bm.limit; step++ {
+				to = Space{File: to.File + dir.file, Rank: to.Rank + dir.rank}
+				if !onBoard(to) {
+					break
+				}
+
+				empty := !occupied(to)
+				if !screened {
+					screened = !empty
+					continue
+				}
+
+				if !visit(to, bm.move && empty, bm.capture) {
+					return false
+				}
+				if !empty || bm.hop == 'g' {
+					break
+				}
+			}
+		}
+	}
+	return true
+}
+
+// betzaTargets returns the empty spaces that a piece of color c on sq may
+// move to with moves, and the spaces that it attacks, which are the
+// spaces that it could capture on if the other player had a piece there.
+func (g *Game) betzaTargets(moves []betzaMove, c Color, sq int) (quiet, attacks Bitboard) {
+	occupied := g.occupied()
+	onBoard := func(s Space) bool {
+		return s.within(8, 8)
+	}
+
+	walkBetza(moves, spaceOf(sq), turnsOf(c), onBoard, occupied.Has, func(to Space, move, capture bool) bool {
+		bit := squareBit(squareOf(to))
+		if move {
+			quiet |= bit
+		}
+		if capture {
+			attacks |= bit
+		}
+		return true
+	})
+	return quiet, attacks
+}
@@ -0,0 +1,164 @@
+package chess
+
+import "math/bits"
+
+// Bitboard is a set of spaces, stored as one bit for each space
+// on the board. a1 is the least significant bit, followed by b1,
+// and so on until h8, which is the most significant bit. Boards
+// larger than 8x8 do not fit in a Bitboard.
+type Bitboard uint64
+
+// Has returns if s is in b.
+func (b Bitboard) Has(s Space) bool {
+	return s.within(8, 8) && b&squareBit(squareOf(s)) != 0
+}
+
+// Count returns the number of spaces in b.
+func (b Bitboard) Count() int {
+	return bits.OnesCount64(uint64(b))
+}
+
+// Spaces returns the spaces in b, starting from a1.
+func (b Bitboard) Spaces() []Space {
+	spaces := make([]Space, 0, b.Count())
+	for b != 0 {
+		spaces = append(spaces, spaceOf(b.pop()))
+	}
+	return spaces
+}
+
+// pop removes the lowest square from b and returns it.
+func (b *Bitboard) pop() int {
+	sq := b.lowest()
+	*b &= *b - 1
+	return sq
+}
+
+// lowest returns the lowest square in b.
+func (b Bitboard) lowest() int {
+	return bits.TrailingZeros64(uint64(b))
+}
+
+// highest returns the highest square in b.
+func (b Bitboard) highest() int {
+	return 63 - bits.LeadingZeros64(uint64(b))
+}
+
+// squareOf returns the square of s, which is
+// the index of its bit in a Bitboard.
+func squareOf(s Space) int {
+	return s.Rank*8 + s.File
+}
+
+func spaceOf(sq int) Space {
+	return Space{File: sq % 8, Rank: sq / 8}
+}
+
+func squareBit(sq int) Bitboard {
+	return 1 << uint(sq)
+}
+
+// colorIndex returns the index used for c in
+// arrays which are indexed by color.
+func colorIndex(c Color) int {
+	if c == White {
+		return 1
+	}
+	return 0
+}
+
+type offset struct {
+	file, rank int
+}
+
+// the directions that sliding pieces move in. the first four point
+// towards higher squares, and the last four point towards lower squares.
+var directions = [8]offset{
+	{file: 0, rank: 1},
+	{file: 1, rank: 0},
+	{file: 1, rank: 1},
+	{file: -1, rank: 1},
+	{file: 0, rank: -1},
+	{file: -1, rank: 0},
+	{file: 1, rank: -1},
+	{file: -1, rank: -1},
+}
+
+var knightOffsets = [8]offset{
+	{file: 1, rank: 2},
+	{file: 2, rank: 1},
+	{file: 1, rank: -2},
+	{file: 2, rank: -1},
+	{file: -1, rank: 2},
+	{file: -2, rank: 1},
+	{file: -1, rank: -2},
+	{file: -2, rank: -1},
+}
+
+// precomputed attack tables, indexed by square
+var (
+	knightAttacks [64]Bitboard
+	kingAttacks   [64]Bitboard
+
+	// indexed by [colorIndex][square]
+	pawnAttacks [2][64]Bitboard
+
+	// every square in a direction until the edge of the
+	// board, indexed by [direction][square]
+	rays [8][64]Bitboard
+)
+
+func init() {
+	for sq := 0; sq < 64; sq++ {
+		s := spaceOf(sq)
+
+		knightAttacks[sq] = offsetsFrom(s, knightOffsets[:])
+		kingAttacks[sq] = offsetsFrom(s, directions[:])
+		pawnAttacks[colorIndex(White)][sq] = offsetsFrom(s, []offset{{file: -1, rank: 1}, {file: 1, rank: 1}})
+		pawnAttacks[colorIndex(Black)][sq] = offsetsFrom(s, []offset{{file: -1, rank: -1}, {file: 1, rank: -1}})
+
+		for dir, off := range directions {
+			cur := Space{File: s.File + off.file, Rank: s.Rank + off.rank}
+			for cur.within(8, 8) {
+				rays[dir][sq] |= squareBit(squareOf(cur))
+				cur = Space{File: cur.File + off.file, Rank: cur.Rank + off.rank}
+			}
+		}
+	}
+}
+
+// offsetsFrom returns each space that is an offset away from s.
+func offsetsFrom(s Space, offsets []offset) Bitboard {
+	var b Bitboard
+	for _, off := range offsets {
+		to := Space{File: s.File + off.file, Rank: s.Rank + off.rank}
+		if to.within(8, 8) {
+			b |= squareBit(squareOf(to))
+		}
+	}
+	return b
+}
+
+// rayAttacks returns the spaces seen by a sliding piece on sq
+// looking in direction dir, stopping at the first occupied space.
+func rayAttacks(dir, sq int, occupied Bitboard) Bitboard {
+	attacks := rays[dir][sq]
+	if blockers := attacks & occupied; blockers != 0 {
+		blocker := blockers.lowest()
+		if dir >= 4 {
+			blocker = blockers.highest()
+		}
+		attacks &^= rays[dir][blocker]
+	}
+	return attacks
+}
+
+func rookAttacks(sq int, occupied Bitboard) Bitboard {
+	return rayAttacks(0, sq, occupied) | rayAttacks(1, sq, occupied) |
+		rayAttacks(4, sq, occupied) | rayAttacks(5, sq, occupied)
+}
+
+func bishopAttacks(sq int, occupied Bitboard) Bitboard {
+	return rayAttacks(2, sq, occupied) | rayAttacks(3, sq, occupied) |
+		rayAttacks(6, sq, occupied) | rayAttacks(7, sq, occupied)
+}
@@ -0,0 +1,300 @@
+package chess
+
+import "fmt"
+
+// the largest board that a game may be played on
+const (
+	maxFiles   = 16
+	maxRanks   = 16
+	maxSquares = maxFiles * maxRanks
+)
+
+// squareSet is a set of squares on a board of any size, which
+// is used for boards that are too large for a Bitboard.
+type squareSet [maxSquares / 64]uint64
+
+func (s *squareSet) add(sq int) {
+	s[sq/64] |= 1 << uint(sq%64)
+}
+
+func (s *squareSet) remove(sq int) {
+	s[sq/64] &^= 1 << uint(sq%64)
+}
+
+func (s *squareSet) has(sq int) bool {
+	return s[sq/64]&(1<<uint(sq%64)) != 0
+}
+
+// the moves of the standard pieces in Betza notation, which are used on
+// boards that are not 8x8. Double steps, en passant and promotions of
+// pawns are handled by the move generator.
+var standardBetza = [...]string{
+	PiecePawn:   "fmWfcF",
+	PieceRook:   "R",
+	PieceKnight: "N",
+	PieceBishop: "B",
+	PieceQueen:  "Q",
+	PieceKing:   "K",
+}
+
+// the parsed moves of standardBetza, indexed by PieceType
+var standardPieceMoves [len(standardBetza)][]betzaMove
+
+func init() {
+	for t, betza := range standardBetza {
+		if betza == "" {
+			continue
+		}
+		moves, err := parseBetza(betza)
+		if err != nil {
+			panic(err)
+		}
+		standardPieceMoves[t] = moves
+	}
+}
+
+// betzaOf returns the moves of pieces of type t.
+func betzaOf(t PieceType) []betzaMove {
+	if t >= firstFairyPiece {
+		return t.fairy().moves
+	}
+	return standardPieceMoves[t]
+}
+
+// Size returns the number of files and ranks on g's board.
+func (g *Game) Size() (files, ranks int) {
+	if g.files == 0 {
+		return 8, 8
+	}
+	return g.files, g.ranks
+}
+
+// OnBoard returns if s is on g's board.
+func (g *Game) OnBoard(s Space) bool {
+	files, ranks := g.Size()
+	return s.within(files, ranks)
+}
+
+// bitboards returns if g's pieces are tracked by Bitboards, which is
+// only possible on an 8x8 board. On other boards, the pieces are only
+// tracked by the mailbox and whites.
+func (g *Game) bitboards() bool {
+	return g.files == 0
+}
+
+// square returns the index of s in g's mailbox. On an 8x8
+// board, it is the same as the index of its bit in a Bitboard.
+func (g *Game) square(s Space) int {
+	files, _ := g.Size()
+	return s.Rank*files + s.File
+}
+
+// space returns the space of the square sq.
+func (g *Game) space(sq int) Space {
+	files, _ := g.Size()
+	return Space{File: sq % files, Rank: sq / files}
+}
+
+// occupiedAt returns if there is a piece on s, which must be on the board.
+func (g *Game) occupiedAt(s Space) bool {
+	return g.mailbox[g.square(s)] != PieceNone
+}
+
+// squaresOf returns the squares of c's pieces, from lowest to highest.
+func (g *Game) squaresOf(c Color) []int {
+	if g.bitboards() {
+		own := g.colors[colorIndex(c)]
+		squares := make([]int, 0, own.Count())
+		for own != 0 {
+			squares = append(squares, own.pop())
+		}
+		return squares
+	}
+
+	var squares []int
+	files, ranks := g.Size()
+	for sq := 0; sq < files*ranks; sq++ {
+		if g.mailbox[sq] != PieceNone && g.colorAt(sq) == c {
+			squares = append(squares, sq)
+		}
+	}
+	return squares
+}
+
+// spacesIn returns the spaces of the squares in set, starting from a1.
+func (g *Game) spacesIn(set squareSet) []Space {
+	var spaces []Space
+	files, ranks := g.Size()
+	for sq := 0; sq < files*ranks; sq++ {
+		if set.has(sq) {
+			spaces = append(spaces, g.space(sq))
+		}
+	}
+	return spaces
+}
+
+// Board returns the game board in its current state, which works for
+// boards of any size. Access board contents with [file][rank].
+func (g *Game) Board() [][]Piece {
+	files, ranks := g.Size()
+	board := make([][]Piece, files)
+	for file := range board {
+		board[file] = make([]Piece, ranks)
+		for rank := range board[file] {
+			if p, ok := g.PieceAt(Space{File: file, Rank: rank}); ok {
+				board[file][rank] = p
+			}
+		}
+	}
+	return board
+}
+
+// InitCustomBoard initializes g to a custom chess layout on a board of
+// any size up to 16x16, keeping its variant. The pieces are stored in
+// [file][rank] form, and every file must have the same number of ranks.
+// Kings castle with the rooks in the corners, if they are given the
+// right to. It panics if the board is empty or too large.
+func (g *Game) InitCustomBoard(pieces [][]Piece) {
+	files, ranks := len(pieces), 0
+	if files > 0 {
+		ranks = len(pieces[0])
+	}
+	if files == 0 || ranks == 0 || files > maxFiles || ranks > maxRanks {
+		panic(fmt.Sprintf("invalid board size %dx%d", files, ranks))
+	}
+
+	*g = Game{variant: g.variant}
+	if files != 8 || ranks != 8 {
+		g.files, g.ranks = files, ranks
+	}
+	g.Castles.WhiteFiles = outerCastleFiles(files)
+	g.Castles.BlackFiles = outerCastleFiles(files)
+	for file, pieceFile := range pieces {
+		if len(pieceFile) != ranks {
+			panic(fmt.Sprintf("file %d has %d ranks instead of %d", file, len(pieceFile), ranks))
+		}
+		for rank, piece := range pieceFile {
+			if piece.Type != PieceNone {
+				g.put(g.square(Space{File: file, Rank: rank}), piece.Type, piece.Color)
+			}
+		}
+	}
+}
+
+// mailboxMoves appends the moves of a piece of type t and color c on from
+// to moves, on a board that is not 8x8. It works like pseudoMovesFrom,
+// and pawns on their second rank may move two spaces up.
+func (g *Game) mailboxMoves(t PieceType, c Color, from int, promotions []PieceType, moves []move) []move {
+	_, ranks := g.Size()
+	add := func(to Space, enPassant bool) {
+		mv := move{from: from, to: g.square(to), enPassant: enPassant}
+		if t == PiecePawn && (to.Rank == 0 || to.Rank == ranks-1) {
+			for _, promotion := range promotions {
+				mv.promotion = promotion
+				moves = append(moves, mv)
+			}
+			return
+		}
+		moves = append(moves, mv)
+	}
+
+	s := g.space(from)
+	walkBetza(betzaOf(t), s, turnsOf(c), g.OnBoard, g.occupiedAt, func(to Space, quiet, capture bool) bool {
+		sq := g.square(to)
+		switch {
+		case quiet:
+			add(to, false)
+		case capture && g.mailbox[sq] != PieceNone && g.colorAt(sq) != c:
+			add(to, false)
+		case capture && t == PiecePawn && g.hasEnPassant() && to == g.EnPassant:
+			add(to, true)
+		}
+		return true
+	})
+
+	if t == PiecePawn {
+		forward, second := 1, 1
+		if c == Black {
+			forward, second = -1, ranks-2
+		}
+		one := Space{File: s.File, Rank: s.Rank + forward}
+		two := Space{File: s.File, Rank: s.Rank + 2*forward}
+		if s.Rank == second && g.OnBoard(two) && !g.occupiedAt(one) && !g.occupiedAt(two) {
+			add(two, false)
+		}
+	}
+	return moves
+}
+
+// mailboxAttacked returns if any of by's pieces attack
+// sq, on a board that is not 8x8.
+func (g *Game) mailboxAttacked(sq int, by Color) bool {
+	target := g.space(sq)
+	for _, from := range g.squaresOf(by) {
+		reached := !walkBetza(betzaOf(g.mailbox[from]), g.space(from), turnsOf(by), g.OnBoard, g.occupiedAt, func(to Space, quiet, capture bool) bool {
+			return !capture || to != target
+		})
+		if reached {
+			return true
+		}
+	}
+	return false
+}
+
+// mailboxSeeing returns the spaces that p can see,
+// on a board that is not 8x8.
+func (g *Game) mailboxSeeing(p Piece) []Space {
+	var seeing squareSet
+	sq := g.square(p.Location)
+	for _, mv := range g.mailboxMoves(p.Type, p.Color, sq, promotions[:1], nil) {
+		seeing.add(mv.to)
+	}
+	if p.Type == PieceKing {
+		for _, kingSide := range [...]bool{true, false} {
+			kingFrom, kingTo, _, _ := g.castleSquares(p.Color, kingSide)
+			if kingFrom == sq && g.canCastle(p.Color, kingSide) {
+				seeing.add(kingTo)
+			}
+		}
+	}
+	return g.spacesIn(seeing)
+}
+
+// sees returns if p can see s.
+func (g *Game) sees(p Piece, s Space) bool {
+	if g.bitboards() {
+		return g.Variant().Seeing(g, p).Has(s)
+	}
+	for _, seen := range g.mailboxSeeing(p) {
+		if seen == s {
+			return true
+		}
+	}
+	return false
+}
+
+// mailboxMaterial returns which case of insufficient material
+// applies to the game, on a board that is not 8x8.
+func (g *Game) mailboxMaterial() MaterialCase {
+	var others []Piece
+	for _, c := range [...]Color{White, Black} {
+		for _, sq := range g.squaresOf(c) {
+			if g.mailbox[sq] != PieceKing {
+				others = append(others, g.pieceOn(sq))
+			}
+		}
+	}
+
+	switch {
+	case len(others) == 0:
+		return MaterialKingVsKing
+	case len(others) == 1 && (others[0].Type == PieceBishop || others[0].Type == PieceKnight):
+		return MaterialKingMinorVsKing
+	}
+	for _, p := range others {
+		if p.Type != PieceBishop || p.Location.Color() != others[0].Location.Color() {
+			return MaterialSufficient
+		}
+	}
+	return MaterialSameColoredBishops
+}
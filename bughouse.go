@@ -0,0 +1,109 @@
+package chess
+
+// Bughouse is the Variant played on each board of a BughouseMatch. It is
+// played like Crazyhouse, except that captured pieces go to the pocket of
+// the capturing player's partner on the other board, which is done by the
+// match rather than the board. Since a board's pockets may change from the
+// other board, moves on a board in a match should not be taken back.
+type Bughouse struct {
+	Crazyhouse
+}
+
+// Name returns "Bughouse".
+func (Bughouse) Name() string {
+	return "Bughouse"
+}
+
+// AfterMove keeps track of which pieces have been promoted.
+func (Bughouse) AfterMove(g *Game, m Move, captured PieceType) {
+	g.Promoted = movePromoted(g.Promoted, m)
+}
+
+// BughouseMove is a move made on one of the boards of a BughouseMatch.
+type BughouseMove struct {
+	// Board is the index of the board that the move was made on.
+	Board int
+	Move  Move
+}
+
+// BughouseMatch is a game of Bughouse, which is played by two teams of two
+// players on two boards. White on the first board is partners with black
+// on the second board, and black on the first board with white on the
+// second. The first board is written as "A", and the second as "B".
+type BughouseMatch struct {
+	Boards [2]*Game
+
+	// History holds every move of the match, in the order they were made.
+	History []BughouseMove
+
+	// Completion is the completion state of the match, which ends once
+	// either board's game ends. Since each team plays both colors, Winner
+	// is the color that the winning team plays on the first board.
+	Completion CompletionState
+}
+
+// Init sets up both boards of the match to the classic chess layout.
+func (b *BughouseMatch) Init() {
+	*b = BughouseMatch{}
+	for i := range b.Boards {
+		b.Boards[i] = &Game{}
+		b.Boards[i].InitVariant(Bughouse{})
+	}
+}
+
+// Partner returns the board and color of the partner of c on board.
+func (b *BughouseMatch) Partner(board int, c Color) (int, Color) {
+	return 1 - board, c.Other()
+}
+
+// MakeMove makes m on board, and gives any piece that it captures to the
+// partner of m's player. Promoted pieces are given back as pawns.
+func (b *BughouseMatch) MakeMove(board int, m Move) error {
+	if b.Completion.Done {
+		return ErrGameOver
+	}
+
+	game := b.Boards[board]
+	captured := PieceNone
+	if p, ok := game.PieceAt(m.To); ok && !m.Drop {
+		captured = p.Type
+		if game.Promoted.Has(m.To) {
+			captured = PiecePawn
+		}
+	}
+	if m.EnPassant {
+		captured = PiecePawn
+	}
+
+	if err := game.MakeMove(m); err != nil {
+		return err
+	}
+	if captured != PieceNone {
+		partnerBoard, partner := b.Partner(board, m.Moving.Color)
+		b.Boards[partnerBoard].Pockets.Of(partner)[captured]++
+	}
+
+	b.History = append(b.History, BughouseMove{Board: board, Move: m})
+	b.updateCompletion(board)
+	return nil
+}
+
+// Resign ends the match with c on board resigning for their team.
+func (b *BughouseMatch) Resign(board int, c Color) error {
+	if b.Completion.Done {
+		return ErrGameOver
+	}
+	if err := b.Boards[board].Resign(c); err != nil {
+		return err
+	}
+	b.updateCompletion(board)
+	return nil
+}
+
+// updateCompletion ends the match if the game on board has ended.
+func (b *BughouseMatch) updateCompletion(board int) {
+	b.Completion = b.Boards[board].Completion
+	if board == 1 && b.Completion.Done && !b.Completion.Draw {
+		b.Completion.Winner = b.Completion.Winner.Other()
+	}
+}
@@ -0,0 +1,91 @@
+package chess
+
+import "sync"
+
+// the types of the archbishop and chancellor of Capablanca chess and
+// Grand Chess. they are registered the first time either variant is
+// played, so that programs which do not play them keep every fairy
+// piece slot, and the letters A and C, for their own pieces.
+var (
+	registerCapablanca sync.Once
+	pieceArchbishop    PieceType
+	pieceChancellor    PieceType
+)
+
+// capablancaPieces returns the types of the archbishop and chancellor,
+// registering them if they have not been registered yet. If a piece with
+// the same name and moves has already been registered, it is used
+// instead. It panics if they cannot be registered.
+func capablancaPieces() (archbishop, chancellor PieceType) {
+	registerCapablanca.Do(func() {
+		pieceArchbishop = registeredPiece(Archbishop)
+		pieceChancellor = registeredPiece(Chancellor)
+	})
+	return pieceArchbishop, pieceChancellor
+}
+
+// registeredPiece returns the type of the registered piece with the same
+// name and moves as def, registering def if there is not one.
+func registeredPiece(def PieceDef) PieceType {
+	for _, t := range FairyPieces() {
+		if registered := t.fairy().def; registered.Name == def.Name && registered.Betza == def.Betza {
+			return t
+		}
+	}
+	return MustRegisterPiece(def)
+}
+
+// Capablanca is the Variant played on a 10x8 board, where each player also
+// has an archbishop, which moves as a bishop or a knight, and a chancellor,
+// which moves as a rook or a knight. Pawns may also promote to either of
+// them, and the king moves three files towards the rook to castle.
+type Capablanca struct {
+	Standard
+}
+
+// Name returns "Capablanca".
+func (Capablanca) Name() string {
+	return "Capablanca"
+}
+
+// registerPieces registers the archbishop and chancellor.
+func (Capablanca) registerPieces() {
+	capablancaPieces()
+}
+
+// Init sets up the pieces with the archbishop between the queen's knight
+// and bishop, and the chancellor between the king's bishop and knight.
+func (Capablanca) Init(g *Game) {
+	archbishop, chancellor := capablancaPieces()
+	back := []PieceType{PieceRook, PieceKnight, archbishop, PieceBishop, PieceQueen, PieceKing, PieceBishop, chancellor, PieceKnight, PieceRook}
+	board := make([][]Piece, len(back))
+	for file, t := range back {
+		board[file] = make([]Piece, 8)
+		board[file][0] = Piece{Type: t, Color: White}
+		board[file][1] = Piece{Type: PiecePawn, Color: White}
+		board[file][6] = Piece{Type: PiecePawn, Color: Black}
+		board[file][7] = Piece{Type: t, Color: Black}
+	}
+
+	g.InitCustomBoard(board)
+	g.Castles.Set(White, true, true)
+	g.Castles.Set(White, false, true)
+	g.Castles.Set(Black, true, true)
+	g.Castles.Set(Black, false, true)
+}
+
+// Moves appends all of c's legal moves to moves, where pawns may
+// also promote to an archbishop or a chancellor.
+func (Capablanca) Moves(g *Game, c Color, moves []Move) []Move {
+	archbishop, chancellor := capablancaPieces()
+	for _, mv := range g.standardMoves(c, nil) {
+		moves = append(moves, g.moveOf(mv))
+		if mv.promotion == PieceQueen {
+			for _, t := range [...]PieceType{archbishop, chancellor} {
+				mv.promotion = t
+				moves = append(moves, g.moveOf(mv))
+			}
+		}
+	}
+	return moves
+}
@@ -0,0 +1,308 @@
+package chess
+
+import (
+	"math/rand"
+	"strconv"
+)
+
+// CastleFiles holds the files that a player's king and
+// rooks start on, which decide how the player castles.
+type CastleFiles struct {
+	King      int
+	KingRook  int
+	QueenRook int
+}
+
+// ClassicCastleFiles are the castle files of classic chess,
+// where the king starts on the e-file and rooks on the a- and h-files.
+var ClassicCastleFiles = CastleFiles{King: 4, KingRook: 7, QueenRook: 0}
+
+type castlingRights struct {
+	BlackKing, BlackQueen bool
+	WhiteKing, WhiteQueen bool
+
+	// the files that each player's king and rooks started on,
+	// which are only different from classic chess in Chess960
+	BlackFiles, WhiteFiles CastleFiles
+}
+
+// classicCastlingRights are the castling rights at the start of classic chess.
+var classicCastlingRights = castlingRights{
+	BlackKing:  true,
+	BlackQueen: true,
+	WhiteKing:  true,
+	WhiteQueen: true,
+	BlackFiles: ClassicCastleFiles,
+	WhiteFiles: ClassicCastleFiles,
+}
+
+// Files returns the castle files of c.
+func (r castlingRights) Files(c Color) CastleFiles {
+	if c == White {
+		return r.WhiteFiles
+	}
+	return r.BlackFiles
+}
+
+// SetFiles sets the castle files of c.
+func (r *castlingRights) SetFiles(c Color, files CastleFiles) {
+	if c == White {
+		r.WhiteFiles = files
+	} else {
+		r.BlackFiles = files
+	}
+}
+
+// Can returns if c may still castle on the king's side
+// if kingSide is true, or the queen's side otherwise.
+func (r castlingRights) Can(c Color, kingSide bool) bool {
+	switch {
+	case c == White && kingSide:
+		return r.WhiteKing
+	case c == White:
+		return r.WhiteQueen
+	case kingSide:
+		return r.BlackKing
+	default:
+		return r.BlackQueen
+	}
+}
+
+// Set sets if c may castle on the king's side
+// if kingSide is true, or the queen's side otherwise.
+func (r *castlingRights) Set(c Color, kingSide, can bool) {
+	switch {
+	case c == White && kingSide:
+		r.WhiteKing = can
+	case c == White:
+		r.WhiteQueen = can
+	case kingSide:
+		r.BlackKing = can
+	default:
+		r.BlackQueen = can
+	}
+}
+
+// Chess960 returns if the game's kings or rooks started somewhere
+// other than where they do in classic chess.
+func (g *Game) Chess960() bool {
+	files, _ := g.Size()
+	outer := outerCastleFiles(files)
+	return g.Castles.WhiteFiles != outer || g.Castles.BlackFiles != outer
+}
+
+// outerCastleFiles returns the castle files on a board with the given
+// number of files where the king starts in the middle and the rooks
+// start in the corners, which on an 8x8 board are the ClassicCastleFiles.
+func outerCastleFiles(files int) CastleFiles {
+	return CastleFiles{King: files / 2, KingRook: files - 1, QueenRook: 0}
+}
+
+// backRank returns the first square of c's back rank.
+func (g *Game) backRank(c Color) int {
+	if c == White {
+		return 0
+	}
+	files, ranks := g.Size()
+	return (ranks - 1) * files
+}
+
+// castleSquares returns the squares that the king and rook start on and move
+// to when c castles on the king's side if kingSide is true, or the
+// queen's side otherwise. In every start position, the king ends on
+// the second file from the edge and the rook ends next to it, which
+// are the g- or c-file and the f- or d-file on an 8x8 board.
+func (g *Game) castleSquares(c Color, kingSide bool) (kingFrom, kingTo, rookFrom, rookTo int) {
+	rank := g.backRank(c)
+	files := g.Castles.Files(c)
+	if kingSide {
+		last, _ := g.Size()
+		return rank + files.King, rank + last - 2, rank + files.KingRook, rank + last - 3
+	}
+	return rank + files.King, rank + 2, rank + files.QueenRook, rank + 3
+}
+
+// canCastle returns if c has the right to castle on the given side,
+// and every space that the king and rook move across is empty. It
+// does not consider if the king castles out of or through check.
+func (g *Game) canCastle(c Color, kingSide bool) bool {
+	if !g.Castles.Can(c, kingSide) {
+		return false
+	}
+
+	kingFrom, kingTo, rookFrom, rookTo := g.castleSquares(c, kingSide)
+	if g.mailbox[kingFrom] != PieceKing || g.colorAt(kingFrom) != c || g.mailbox[rookFrom] != PieceRook || g.colorAt(rookFrom) != c {
+		return false
+	}
+
+	// the castling king and rook may be in each other's way
+	for _, path := range [...][2]int{{kingFrom, kingTo}, {rookFrom, rookTo}} {
+		low, high := span(path[0], path[1])
+		for sq := low; sq <= high; sq++ {
+			if sq != kingFrom && sq != rookFrom && (g.mailbox[sq] != PieceNone || g.Duck&squareBit(sq) != 0) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// castleTargets returns the spaces that c's king on sq may castle to,
+// as long as the king does not castle out of or through check.
+func (g *Game) castleTargets(c Color, sq int) Bitboard {
+	var targets Bitboard
+	for _, kingSide := range [...]bool{true, false} {
+		kingFrom, kingTo, _, _ := g.castleSquares(c, kingSide)
+		if kingFrom == sq && g.canCastle(c, kingSide) {
+			targets |= squareBit(kingTo)
+		}
+	}
+	return targets
+}
+
+// castleMoves appends the legal castles of c's king on sq to moves.
+func (g *Game) castleMoves(c Color, sq int, moves []move) []move {
+	for _, kingSide := range [...]bool{true, false} {
+		kingFrom, kingTo, _, _ := g.castleSquares(c, kingSide)
+		if kingFrom != sq || !g.canCastle(c, kingSide) {
+			continue
+		}
+
+		// no castling out of or through check
+		safe := true
+		low, high := span(kingFrom, kingTo)
+		for sq := low; sq <= high && safe; sq++ {
+			safe = !g.attacked(sq, c.Other())
+		}
+		if !safe {
+			continue
+		}
+
+		// the rook may have been blocking an attack on the king's space
+		mv := move{from: kingFrom, to: kingTo, castle: true}
+		u := g.makeMove(mv)
+		inCheck := g.inCheck(c)
+		g.unmakeMove(u)
+		if !inCheck {
+			moves = append(moves, mv)
+		}
+	}
+	return moves
+}
+
+// castleRook returns the squares that the rook starts on and moves to
+// for a castle by c where the king moves to kingTo.
+func (g *Game) castleRook(c Color, kingTo int) (rookFrom, rookTo int) {
+	files, _ := g.Size()
+	_, _, rookFrom, rookTo = g.castleSquares(c, kingTo%files == files-2)
+	return rookFrom, rookTo
+}
+
+// castleTo returns the space that c's king moves to if moving it from one
+// square to another is written as a castle, either by moving the king two
+// files to its castled space, or by moving the king onto its own castling
+// rook as UCI does in Chess960. On boards wider than 8 files, the king
+// may move further than two files to castle.
+func (g *Game) castleTo(c Color, from, to int) (int, bool) {
+	for _, kingSide := range [...]bool{true, false} {
+		kingFrom, kingTo, rookFrom, _ := g.castleSquares(c, kingSide)
+		if from != kingFrom || !g.Castles.Can(c, kingSide) {
+			continue
+		}
+		diff := to - from
+		if to == rookFrom || to == kingTo && (diff >= 2 || diff <= -2) {
+			return kingTo, true
+		}
+	}
+	return 0, false
+}
+
+// updateCastles removes castling rights which
+// depend on the piece that was on sq.
+func (g *Game) updateCastles(sq int) {
+	for _, c := range [...]Color{White, Black} {
+		rank := g.backRank(c)
+		files := g.Castles.Files(c)
+		switch sq {
+		case rank + files.King:
+			g.Castles.Set(c, true, false)
+			g.Castles.Set(c, false, false)
+		case rank + files.KingRook:
+			g.Castles.Set(c, true, false)
+		case rank + files.QueenRook:
+			g.Castles.Set(c, false, false)
+		}
+	}
+}
+
+// span returns the lowest and highest of a and b, so that the squares
+// on the same rank from a to b, inclusive, are low through high.
+func span(a, b int) (low, high int) {
+	if a > b {
+		return b, a
+	}
+	return a, b
+}
+
+// InitChess960 initializes g to one of the 960 start positions of
+// Chess960, numbered 0 through 959 as described by Reinhard Scharnagl.
+// Position 518 is the classic chess layout. g keeps its variant.
+// It panics if id is not a valid position number.
+func (g *Game) InitChess960(id int) {
+	if id < 0 || id >= 960 {
+		panic("invalid Chess960 position " + strconv.Itoa(id))
+	}
+
+	var rank [8]PieceType
+	var files CastleFiles
+
+	// placeEmpty places t on the nth empty file
+	placeEmpty := func(n int, t PieceType) int {
+		for file := range rank {
+			if rank[file] != PieceNone {
+				continue
+			}
+			if n == 0 {
+				rank[file] = t
+				return file
+			}
+			n--
+		}
+		panic("unreachable")
+	}
+
+	rank[id%4*2+1] = PieceBishop
+	id /= 4
+	rank[id%4*2] = PieceBishop
+	id /= 4
+	placeEmpty(id%6, PieceQueen)
+	id /= 6
+
+	// the second knight is placed among the spaces left after the first
+	knights := [...][2]int{{0, 0}, {0, 1}, {0, 2}, {0, 3}, {1, 1}, {1, 2}, {1, 3}, {2, 2}, {2, 3}, {3, 3}}[id]
+	placeEmpty(knights[0], PieceKnight)
+	placeEmpty(knights[1], PieceKnight)
+
+	// the remaining three files are always rook, king, rook
+	files.QueenRook = placeEmpty(0, PieceRook)
+	files.King = placeEmpty(0, PieceKing)
+	files.KingRook = placeEmpty(0, PieceRook)
+
+	*g = Game{variant: g.variant, Castles: classicCastlingRights}
+	g.Castles.WhiteFiles = files
+	g.Castles.BlackFiles = files
+	for file, pieceType := range rank {
+		g.put(file, pieceType, White)
+		g.put(8+file, PiecePawn, White)
+		g.put(56+file, pieceType, Black)
+		g.put(48+file, PiecePawn, Black)
+	}
+}
+
+// InitChess960Random initializes g to a random Chess960 start position,
+// and returns the number of the position.
+func (g *Game) InitChess960Random() int {
+	id := rand.Intn(960)
+	g.InitChess960(id)
+	return id
+}
@@ -0,0 +1,184 @@
+// Package clock implements chess clocks which keep
+// time for a chess.Game under a time control.
+package clock
+
+import (
+	"errors"
+	"time"
+
+	"github.com/deanveloper/chess"
+)
+
+// ErrFlagged is the error returned when a player tries
+// to move after running out of time.
+var ErrFlagged = errors.New("player ran out of time")
+
+// Clock is a chess clock which keeps time for a game.
+type Clock struct {
+	// Now returns the current time. It is time.Now by default,
+	// and may be replaced to control the clock, such as in tests.
+	Now func() time.Time
+
+	control Control
+	game    *chess.Game
+
+	// indexed by colorIndex
+	remaining   [2]time.Duration
+	period      [2]int
+	periodMoves [2]int
+
+	// time spent on the current move before the clock
+	// was last started, and when it was last started
+	spent   time.Duration
+	started time.Time
+	running bool
+}
+
+// New returns a stopped clock for game using control,
+// which must have at least one period.
+func New(game *chess.Game, control Control) *Clock {
+	c := &Clock{
+		Now:     time.Now,
+		control: control,
+		game:    game,
+	}
+	for i := range c.remaining {
+		c.remaining[i] = control.Periods[0].Time
+	}
+	return c
+}
+
+// Control returns the time control that c is using.
+func (c *Clock) Control() Control {
+	return c.control
+}
+
+// Running returns if the clock is running.
+func (c *Clock) Running() bool {
+	return c.running
+}
+
+// Start starts the clock of the player to move.
+func (c *Clock) Start() {
+	if c.running || c.game.Completion.Done {
+		return
+	}
+	c.running = true
+	c.started = c.Now()
+}
+
+// Stop stops the clock. Time spent on the current move
+// before the clock was stopped still counts for the move.
+func (c *Clock) Stop() {
+	if !c.running {
+		return
+	}
+	c.spent += c.Now().Sub(c.started)
+	c.running = false
+}
+
+// Remaining returns how much time player has left.
+func (c *Clock) Remaining(player chess.Color) time.Duration {
+	remaining := c.remaining[colorIndex(player)]
+	if player == c.game.Turn() {
+		remaining -= c.charged(colorIndex(player), c.elapsed(c.Now()))
+	}
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// CheckFlag returns if the player to move has run out of time,
+// in which case the clock is stopped and the game ends by timeout.
+func (c *Clock) CheckFlag() bool {
+	if c.game.Completion.Done {
+		c.Stop()
+		return false
+	}
+
+	turn := c.game.Turn()
+	if c.Remaining(turn) > 0 {
+		return false
+	}
+
+	c.Stop()
+	c.remaining[colorIndex(turn)] = 0
+	c.game.Timeout(turn)
+	return true
+}
+
+// MakeMove makes m in the game and presses the clock for the player
+// who moved, which starts the opponent's clock. If the player ran out
+// of time before moving, the game ends and ErrFlagged is returned.
+func (c *Clock) MakeMove(m chess.Move) error {
+	if c.CheckFlag() {
+		return ErrFlagged
+	}
+
+	now := c.Now()
+	mover := c.game.Turn()
+	if err := c.game.MakeMove(m); err != nil {
+		return err
+	}
+
+	i := colorIndex(mover)
+	elapsed := c.elapsed(now)
+	period := c.control.Periods[c.period[i]]
+
+	c.remaining[i] -= c.charged(i, elapsed)
+	switch c.control.Method {
+	case Fischer:
+		c.remaining[i] += period.Increment
+	case Bronstein:
+		if elapsed < period.Increment {
+			c.remaining[i] += elapsed
+		} else {
+			c.remaining[i] += period.Increment
+		}
+	}
+
+	// start the next period once enough moves have been made
+	c.periodMoves[i]++
+	if period.Moves > 0 && c.periodMoves[i] == period.Moves {
+		if c.period[i] < len(c.control.Periods)-1 {
+			c.period[i]++
+		}
+		c.periodMoves[i] = 0
+		c.remaining[i] += c.control.Periods[c.period[i]].Time
+	}
+
+	c.spent = 0
+	c.started = now
+	c.running = !c.game.Completion.Done
+	return nil
+}
+
+// elapsed returns the time spent on the current move.
+func (c *Clock) elapsed(now time.Time) time.Duration {
+	if !c.running {
+		return c.spent
+	}
+	return c.spent + now.Sub(c.started)
+}
+
+// charged returns how much of the time spent on a
+// move is taken from the clock of the player at index i.
+func (c *Clock) charged(i int, elapsed time.Duration) time.Duration {
+	if c.control.Method != SimpleDelay {
+		return elapsed
+	}
+
+	delay := c.control.Periods[c.period[i]].Increment
+	if elapsed < delay {
+		return 0
+	}
+	return elapsed - delay
+}
+
+func colorIndex(c chess.Color) int {
+	if c == chess.White {
+		return 1
+	}
+	return 0
+}
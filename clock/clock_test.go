@@ -0,0 +1,181 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/deanveloper/chess"
+	"github.com/deanveloper/chess/clock"
+	"github.com/deanveloper/chess/encoder"
+)
+
+// fakeTime is a time source which only moves when told to.
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeTime) Advance(d time.Duration) {
+	f.now = f.now.Add(d)
+}
+
+func newClock(t *testing.T, control string, method clock.Method) (*chess.Game, *clock.Clock, *fakeTime) {
+	parsed, err := clock.ParseControl(control)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed.Method = method
+
+	game := &chess.Game{}
+	game.InitClassic()
+
+	source := &fakeTime{now: time.Unix(0, 0)}
+	clk := clock.New(game, parsed)
+	clk.Now = source.Now
+	clk.Start()
+
+	return game, clk, source
+}
+
+func play(t *testing.T, game *chess.Game, clk *clock.Clock, alg string) {
+	move, err := encoder.FromAlgebraic(game, alg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := clk.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMethods(t *testing.T) {
+	tests := []struct {
+		method clock.Method
+		want   time.Duration
+	}{
+		{method: clock.Fischer, want: 60*time.Second - 10*time.Second + 5*time.Second},
+		{method: clock.Bronstein, want: 60*time.Second - 10*time.Second + 5*time.Second},
+		{method: clock.SimpleDelay, want: 60*time.Second - 5*time.Second},
+	}
+
+	for _, test := range tests {
+		game, clk, source := newClock(t, "60+5", test.method)
+
+		source.Advance(10 * time.Second)
+		play(t, game, clk, "e4")
+
+		if got := clk.Remaining(chess.White); got != test.want {
+			t.Errorf("%v: white has %v, want %v", test.method, got, test.want)
+		}
+		if got := clk.Remaining(chess.Black); got != 60*time.Second {
+			t.Errorf("%v: black has %v, want %v", test.method, got, 60*time.Second)
+		}
+	}
+
+	// a quick move under Bronstein only gets back the time used
+	game, clk, source := newClock(t, "60+5", clock.Bronstein)
+	source.Advance(2 * time.Second)
+	play(t, game, clk, "e4")
+	if got := clk.Remaining(chess.White); got != 60*time.Second {
+		t.Errorf("quick Bronstein move: white has %v, want %v", got, 60*time.Second)
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	game, clk, source := newClock(t, "2/60:30", clock.Fischer)
+
+	for _, alg := range []string{"Nf3", "Nf6", "Ng1", "Ng8"} {
+		source.Advance(10 * time.Second)
+		play(t, game, clk, alg)
+	}
+
+	want := 60*time.Second - 20*time.Second + 30*time.Second
+	if got := clk.Remaining(chess.White); got != want {
+		t.Errorf("white has %v after the first period, want %v", got, want)
+	}
+}
+
+func TestFlag(t *testing.T) {
+	game, clk, source := newClock(t, "60", clock.Fischer)
+
+	source.Advance(61 * time.Second)
+	move, _ := encoder.FromAlgebraic(game, "e4")
+	if err := clk.MakeMove(move); err != clock.ErrFlagged {
+		t.Fatalf("expected ErrFlagged, got %v", err)
+	}
+	if !game.Completion.Done || game.Completion.Winner != chess.Black || game.Completion.Reason != chess.TerminationTimeout {
+		t.Errorf("expected black to win on time, got %v", game.Completion)
+	}
+
+	// an opponent with only a king cannot win on time
+	game, err := encoder.FromFEN("4k3/8/8/8/8/8/8/3QK3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	control, _ := clock.ParseControl("60")
+	clk = clock.New(game, control)
+	clk.Now = source.Now
+	clk.Start()
+
+	source.Advance(61 * time.Second)
+	if !clk.CheckFlag() {
+		t.Fatal("expected flag to fall")
+	}
+	if !game.Completion.Draw || game.Completion.Reason != chess.TerminationTimeoutVsInsufficientMaterial {
+		t.Errorf("expected a draw, got %v", game.Completion)
+	}
+
+	// a king and minor piece cannot checkmate a lone king, but can
+	// checkmate a king which has other pieces to block it in
+	for fen, draw := range map[string]bool{
+		"4k3/8/8/8/8/8/8/3NK3 b - - 0 1":    true,
+		"4k3/8/8/8/8/8/8/3BK3 b - - 0 1":    true,
+		"4k3/3p4/8/8/8/8/8/3NK3 b - - 0 1":  false,
+		"4k3/8/8/8/8/8/8/2BBK3 b - - 0 1":   false,
+		"4k3/8/8/8/8/8/8/B1B1K3 b - - 0 1":  true,
+		"4k3/8/8/8/8/8/3PP3/4K3 b - - 0 1":  false,
+		"4k3/3pp3/8/8/8/8/8/4K3 b - - 0 1":  true,
+		"4k3/3pp3/8/8/8/8/8/3NK3 b - - 0 1": false,
+	} {
+		game, err := encoder.FromFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := game.Timeout(chess.Black); err != nil {
+			t.Fatal(err)
+		}
+		if game.Completion.Draw != draw {
+			t.Errorf("%s: black flagged, got %v", fen, game.Completion)
+		}
+	}
+}
+
+func TestParseControl(t *testing.T) {
+	for _, control := range []string{"300", "300+3", "40/5400+30:1800+30"} {
+		parsed, err := clock.ParseControl(control)
+		if err != nil {
+			t.Errorf("%s: %v", control, err)
+			continue
+		}
+		if parsed.String() != control {
+			t.Errorf("%s: round trip gave %s", control, parsed.String())
+		}
+	}
+
+	// times may be written in minutes, but increments may not
+	minutes, err := clock.ParseControl("40/90m+30:30m+30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := minutes.String(), "40/5400+30:1800+30"; got != want {
+		t.Errorf("minutes: got %s, want %s", got, want)
+	}
+
+	for _, control := range []string{"", "abc", "0/300", "300+x", "m", "90m+30m", "-5m"} {
+		if _, err := clock.ParseControl(control); err == nil {
+			t.Errorf("%q: expected an error", control)
+		}
+	}
+}
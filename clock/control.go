@@ -0,0 +1,132 @@
+package clock
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Method is the way that a time control's increment is applied.
+type Method byte
+
+// The enum of methods
+const (
+	// Fischer adds the increment to a player's
+	// clock after each of their moves.
+	Fischer Method = iota
+
+	// Bronstein gives back the time that a player used on
+	// each of their moves, up to the increment.
+	Bronstein
+
+	// SimpleDelay, also called US delay, waits for the increment
+	// to pass on each move before the player's clock starts.
+	SimpleDelay
+)
+
+func (m Method) String() string {
+	return [...]string{"Fischer", "Bronstein", "SimpleDelay"}[m]
+}
+
+// Period is a single period of a time control.
+type Period struct {
+	// the number of moves each player must make in the period,
+	// or 0 if the period lasts for the rest of the game.
+	Moves int
+
+	// the time added to each player's clock when the period starts
+	Time time.Duration
+
+	// the increment, or the delay for the Bronstein and
+	// SimpleDelay methods, applied to each move in the period
+	Increment time.Duration
+}
+
+// Control is a time control made of one or more periods. Once
+// every period has been played, the last period repeats if
+// it has a number of moves, such as in "40/7200".
+type Control struct {
+	Periods []Period
+	Method  Method
+}
+
+type controlError struct {
+	control string
+	reason  string
+}
+
+func (c controlError) Error() string {
+	return fmt.Sprintf("parsing time control %q: %s", c.control, c.reason)
+}
+
+// ParseControl parses a time control in the format of the PGN
+// TimeControl tag, where times are in seconds and periods are
+// separated by colons. For instance, "300+3" is 5 minutes with a
+// 3 second increment, and "40/5400+30:1800+30" is 90 minutes for
+// 40 moves followed by 30 minutes for the rest of the game, with
+// a 30 second increment. A period's time may instead be written
+// in minutes with an "m" suffix, so the same control can be
+// written as "40/90m+30:30m+30". Increments are always in seconds.
+// The method of the control is Fischer.
+func ParseControl(control string) (Control, error) {
+	var parsed Control
+
+	for _, periodStr := range strings.Split(control, ":") {
+		var period Period
+
+		rest := periodStr
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			moves, err := strconv.Atoi(rest[:i])
+			if err != nil || moves < 1 {
+				return Control{}, controlError{control: control, reason: "invalid number of moves in " + periodStr}
+			}
+			period.Moves = moves
+			rest = rest[i+1:]
+		}
+
+		if i := strings.IndexByte(rest, '+'); i >= 0 {
+			increment, err := strconv.Atoi(rest[i+1:])
+			if err != nil || increment < 0 {
+				return Control{}, controlError{control: control, reason: "invalid increment in " + periodStr}
+			}
+			period.Increment = time.Duration(increment) * time.Second
+			rest = rest[:i]
+		}
+
+		unit := time.Second
+		if strings.HasSuffix(rest, "m") {
+			unit = time.Minute
+			rest = rest[:len(rest)-1]
+		}
+		amount, err := strconv.Atoi(rest)
+		if err != nil || amount < 0 {
+			return Control{}, controlError{control: control, reason: "invalid time in " + periodStr}
+		}
+		period.Time = time.Duration(amount) * unit
+
+		parsed.Periods = append(parsed.Periods, period)
+	}
+
+	return parsed, nil
+}
+
+// String returns the control in the format of the PGN TimeControl
+// tag, with every time in seconds.
+func (c Control) String() string {
+	periods := make([]string, len(c.Periods))
+	for i, period := range c.Periods {
+		var builder strings.Builder
+		if period.Moves > 0 {
+			builder.WriteString(strconv.Itoa(period.Moves))
+			builder.WriteByte('/')
+		}
+		builder.WriteString(strconv.Itoa(int(period.Time / time.Second)))
+		if period.Increment > 0 {
+			builder.WriteByte('+')
+			builder.WriteString(strconv.Itoa(int(period.Increment / time.Second)))
+		}
+		periods[i] = builder.String()
+	}
+	return strings.Join(periods, ":")
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/deanveloper/chess"
+	"github.com/deanveloper/chess/encoder"
+)
+
+// the bughouse match being played, if any. while it is
+// being played, commands are run on the match instead of the game.
+var match *chess.BughouseMatch
+
+// runBughouseCmd runs a command on the bughouse match.
+func runBughouseCmd(fields []string) bool {
+	switch fields[0] {
+	case "move":
+		if len(fields) < 3 {
+			fmt.Println("command move:")
+			fmt.Println("\tmakes a move on board a or b using algebraic notation")
+			fmt.Println("\tsyntax: move <a|b> <algebraic>")
+			fmt.Println("\tex: `move a e4`, `move b N@f3`")
+			return false
+		}
+		board, ok := parseBoard(fields[1])
+		if !ok {
+			return false
+		}
+		move, err := encoder.FromAlgebraic(match.Boards[board], fields[2])
+		if err != nil {
+			fmt.Println("error:", err.Error())
+			return false
+		}
+		if err := match.MakeMove(board, move); err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		if match.Completion.Done {
+			fmt.Println("game over:", match.Completion, match.Completion.Result())
+		}
+	case "board":
+		printBughouse(match)
+	case "resign":
+		if len(fields) < 2 {
+			fmt.Println("command resign:")
+			fmt.Println("\tthe player to move on board a or b resigns for their team")
+			fmt.Println("\tsyntax: resign <a|b>")
+			return false
+		}
+		board, ok := parseBoard(fields[1])
+		if !ok {
+			return false
+		}
+		if err := match.Resign(board, match.Boards[board].Turn()); err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		fmt.Println("game over:", match.Completion, match.Completion.Result())
+	case "fen":
+		for i, game := range match.Boards {
+			all, err := ioutil.ReadAll(encoder.FENReader(game))
+			if err != nil {
+				fmt.Println("error:", err)
+				return false
+			}
+			fmt.Printf("%c: %s\n", 'a'+i, all)
+		}
+	case "pgn", "bpgn":
+		moves := make(chan chess.BughouseMove, len(match.History))
+		for _, move := range match.History {
+			moves <- move
+		}
+		close(moves)
+		ch := make(chan chess.CompletionState, 1)
+		ch <- match.Completion
+
+		tags := map[string]string{"Variant": "Bughouse"}
+		all, err := ioutil.ReadAll(encoder.BPGNReader(tags, moves, ch))
+		if err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		fmt.Println(string(all))
+	case "replay":
+		if len(fields) < 2 {
+			fmt.Println("command replay:")
+			fmt.Println("\tshows both boards after the first n moves of the match")
+			fmt.Println("\tsyntax: replay <n>")
+			fmt.Printf("\tthe match has %d moves\n", len(match.History))
+			return false
+		}
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 0 || n > len(match.History) {
+			fmt.Printf("n must be a number of moves from 0 to %d\n", len(match.History))
+			return false
+		}
+
+		replayed := &chess.BughouseMatch{}
+		replayed.Init()
+		for _, move := range match.History[:n] {
+			if err := replayed.MakeMove(move.Board, move.Move); err != nil {
+				fmt.Println("error:", err)
+				return false
+			}
+		}
+		printBughouse(replayed)
+	case "exit":
+		match = nil
+		fmt.Println("left the bughouse match")
+	default:
+		fmt.Printf("unknown command: %q\n", fields)
+		fmt.Println("available commands in a bughouse match:")
+		fmt.Println("move <a|b> <algebraic>")
+		fmt.Println("\tmakes a move on board a or b, such as `move a e4` or `move b N@f3`")
+		fmt.Println()
+		fmt.Println("board")
+		fmt.Println("\tprints both boards side by side")
+		fmt.Println()
+		fmt.Println("resign <a|b>")
+		fmt.Println("\tthe player to move on the board resigns for their team")
+		fmt.Println()
+		fmt.Println("fen")
+		fmt.Println("\tprints the FEN of both boards")
+		fmt.Println()
+		fmt.Println("pgn")
+		fmt.Println("\tprints the BPGN of the match")
+		fmt.Println()
+		fmt.Println("replay <n>")
+		fmt.Println("\tprints both boards as they were after the first n moves")
+		fmt.Println()
+		fmt.Println("exit")
+		fmt.Println("\tleaves the match, and goes back to the last game")
+		return false
+	}
+	return true
+}
+
+// parseBoard returns the index of the board named a or b.
+func parseBoard(name string) (int, bool) {
+	switch strings.ToLower(name) {
+	case "a":
+		return 0, true
+	case "b":
+		return 1, true
+	}
+	fmt.Printf("unknown board: %q (must be a or b)\n", name)
+	return 0, false
+}
+
+// printBughouse prints both boards of m side by side, with board b
+// rotated so that partners sit on the same side.
+func printBughouse(m *chess.BughouseMatch) {
+	a, b := boardLines(m.Boards[0].Board(), 0, false), boardLines(m.Boards[1].Board(), 0, true)
+
+	fmt.Printf("   %-43s   %s\n", "board a, "+m.Boards[0].Turn().String()+" to move", "board b, "+m.Boards[1].Turn().String()+" to move")
+	for i := range a {
+		fmt.Println(a[i] + "   " + b[i])
+	}
+
+	fmt.Println()
+	fmt.Printf("   %-43s   %s\n", "black pocket: "+formatPocket(m.Boards[0].Pockets.Black), "white pocket: "+formatPocket(m.Boards[1].Pockets.White))
+	fmt.Printf("   %-43s   %s\n", "white pocket: "+formatPocket(m.Boards[0].Pockets.White), "black pocket: "+formatPocket(m.Boards[1].Pockets.Black))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/deanveloper/chess"
+)
+
+// the kriegspiel game being played, if any. while it is being played,
+// commands are run on it instead of the game. both players share the
+// terminal, so the board only ever shows the pieces of the player to move.
+var kriegspiel *chess.Kriegspiel
+
+// runKriegspielCmd runs a command on the kriegspiel game.
+func runKriegspielCmd(fields []string) bool {
+	switch fields[0] {
+	case "move":
+		if len(fields) < 2 {
+			fmt.Println("command move:")
+			fmt.Println("\tasks the referee to make a move, written as the space")
+			fmt.Println("\tthe piece moves from and the space it moves to")
+			fmt.Println("\tsyntax: move <from><to>[promotion]")
+			fmt.Println("\tex: `move e2e4`, `move e1g1` (castle), `move a7a8n`")
+			return false
+		}
+		from, to, promotion, ok := parseCoordinate(fields[1])
+		if !ok {
+			fmt.Printf("invalid move: %q\n", fields[1])
+			return false
+		}
+		announcement, err := kriegspiel.Attempt(from, to, promotion)
+		if err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		fmt.Println("referee:", announcement)
+		if announcement.Illegal {
+			return true
+		}
+		if kriegspiel.Completion().Done {
+			fmt.Println("game over:", kriegspiel.Completion(), kriegspiel.Completion().Result())
+			return true
+		}
+		fmt.Printf("pass the terminal to %v\n", kriegspiel.Turn())
+	case "board":
+		// once the game is over, both players may see the whole board
+		if kriegspiel.Completion().Done {
+			for _, line := range boardLines(kriegspiel.Game().Board(), 0, false) {
+				fmt.Println(line)
+			}
+			return true
+		}
+
+		view := kriegspiel.View(kriegspiel.Turn())
+		fmt.Printf("   %v's pieces\n", view.Color)
+		for _, line := range boardLines(view.Board(), 0, view.Color == chess.Black) {
+			fmt.Println(line)
+		}
+	case "announcements":
+		for _, announcement := range kriegspiel.Announcements {
+			fmt.Printf("%v: %v\n", announcement.Player, announcement)
+		}
+	case "resign":
+		if err := kriegspiel.Resign(kriegspiel.Turn()); err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		fmt.Println("game over:", kriegspiel.Completion(), kriegspiel.Completion().Result())
+	case "exit":
+		kriegspiel = nil
+		fmt.Println("left the kriegspiel game")
+	default:
+		fmt.Printf("unknown command: %q\n", fields)
+		fmt.Println("available commands in a kriegspiel game:")
+		fmt.Println("move <from><to>[promotion]")
+		fmt.Println("\tasks the referee to make a move, such as `move e2e4` or `move a7a8n`")
+		fmt.Println()
+		fmt.Println("board")
+		fmt.Println("\tprints the pieces of the player to move, or the")
+		fmt.Println("\twhole board once the game is over")
+		fmt.Println()
+		fmt.Println("announcements")
+		fmt.Println("\tlists everything the referee has announced")
+		fmt.Println()
+		fmt.Println("resign")
+		fmt.Println("\tthe player to move resigns the game")
+		fmt.Println()
+		fmt.Println("exit")
+		fmt.Println("\tleaves the game, and goes back to the last game")
+		return false
+	}
+	return true
+}
+
+// parseCoordinate parses a move in coordinate notation, such as
+// "e2e4", or "e7e8q" for promotions.
+func parseCoordinate(coordinate string) (from, to chess.Space, promotion chess.PieceType, ok bool) {
+	if len(coordinate) != 4 && len(coordinate) != 5 {
+		return from, to, promotion, false
+	}
+
+	space := func(s string) (chess.Space, bool) {
+		space := chess.Space{File: int(s[0]) - 'a', Rank: int(s[1]) - '1'}
+		return space, space.File >= 0 && space.File < 8 && space.Rank >= 0 && space.Rank < 8
+	}
+	from, fromOk := space(coordinate[0:2])
+	to, toOk := space(coordinate[2:4])
+	if len(coordinate) == 5 {
+		switch coordinate[4] {
+		case 'q':
+			promotion = chess.PieceQueen
+		case 'r':
+			promotion = chess.PieceRook
+		case 'b':
+			promotion = chess.PieceBishop
+		case 'n':
+			promotion = chess.PieceKnight
+		default:
+			return from, to, promotion, false
+		}
+	}
+	return from, to, promotion, fromOk && toOk
+}
@@ -8,14 +8,51 @@ import (
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 
 	a "github.com/logrusorgru/aurora"
 
 	"github.com/deanveloper/chess"
+	"github.com/deanveloper/chess/clock"
 	"github.com/deanveloper/chess/encoder"
 )
 
 var history []chess.Move
+var undone []chess.Move
+
+// the game's clock, if one has been set
+var clk *clock.Clock
+
+// the FEN of the position the game started from,
+// if it did not start from the classic layout
+var startFEN string
+
+const classicFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// the variants that can be played, by their UCI_Variant names
+var variants = map[string]chess.Variant{
+	"chess":         chess.Standard{},
+	"kingofthehill": chess.KingOfTheHill{},
+	"3check":        chess.ThreeCheck{},
+	"atomic":        chess.Atomic{},
+	"crazyhouse":    chess.Crazyhouse{},
+	"antichess":     chess.Antichess{},
+	"horde":         chess.Horde{},
+	"racingkings":   chess.RacingKings{},
+	"capablanca":    chess.Capablanca{},
+	"grand":         chess.GrandChess{},
+	"duck":          chess.Duck{},
+}
+
+// uciVariant returns the UCI_Variant name of v.
+func uciVariant(v chess.Variant) string {
+	for name, each := range variants {
+		if each.Name() == v.Name() {
+			return name
+		}
+	}
+	return "chess"
+}
 
 var blackAuto [][]string
 var whiteAuto [][]string
@@ -46,6 +83,12 @@ func runCmd(game *chess.Game, fields []string) bool {
 	if len(fields) < 1 {
 		return true
 	}
+	if match != nil {
+		return runBughouseCmd(fields)
+	}
+	if kriegspiel != nil {
+		return runKriegspielCmd(fields)
+	}
 
 	switch fields[0] {
 	case "debug":
@@ -100,6 +143,8 @@ func runCmd(game *chess.Game, fields []string) bool {
 			fmt.Println("\tmakes a move using algebraic notation")
 			fmt.Println("\tsyntax: move <algebraic>")
 			fmt.Println("\tex: `move e4`, `move a8Q`, `move Raxd1")
+			fmt.Println("\tin crazyhouse, pieces are dropped with `move N@f3`")
+			fmt.Println("\tin duck chess, the duck's space follows a comma, such as `move e4,e5`")
 			fmt.Println("\tmore information about algebraic notation:")
 			fmt.Println("\thttps://en.wikipedia.org/wiki/Algebraic_notation_(chess)")
 			return false
@@ -109,12 +154,25 @@ func runCmd(game *chess.Game, fields []string) bool {
 			fmt.Println("error:", err.Error())
 			return false
 		}
-		err = game.MakeMove(move)
+		if clk != nil {
+			err = clk.MakeMove(move)
+		} else {
+			err = game.MakeMove(move)
+		}
+		if err == clock.ErrFlagged {
+			fmt.Println("game over:", game.Completion, game.Completion.Result())
+			return false
+		}
 		if err != nil {
 			fmt.Println("error:", err)
 			return false
 		}
 		history = append(history, move)
+		undone = nil
+
+		if game.Completion.Done {
+			fmt.Println("game over:", game.Completion, game.Completion.Result())
+		}
 
 		var cmds [][]string
 		if game.Turn() == chess.Black {
@@ -127,6 +185,85 @@ func runCmd(game *chess.Game, fields []string) bool {
 		for _, cmd := range cmds {
 			runCmd(game, cmd)
 		}
+	case "moves":
+		var algs []string
+		for _, move := range game.LegalMoves() {
+			algs = append(algs, encoder.Algebraic(move))
+		}
+		fmt.Println(strings.Join(algs, " "))
+	case "resign":
+		if err := game.Resign(game.Turn()); err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		fmt.Println("game over:", game.Completion, game.Completion.Result())
+	case "draw":
+		if len(fields) < 2 {
+			fmt.Println("command draw:")
+			fmt.Println("\toffers, accepts, declines, or claims a draw")
+			fmt.Println("\tsyntax: draw <offer|accept|decline|claim>")
+			fmt.Println("\tex: `draw offer` (offer a draw to your opponent)")
+			fmt.Println("\tex: `draw claim` (claim a draw by the 50 move rule or threefold repetition)")
+			return false
+		}
+
+		var err error
+		switch fields[1] {
+		case "offer":
+			err = game.OfferDraw(game.Turn())
+			if err == nil && !game.Completion.Done {
+				fmt.Printf("%v offers a draw\n", game.Turn())
+			}
+		case "accept":
+			err = game.AcceptDraw(game.Turn())
+		case "decline":
+			err = game.DeclineDraw(game.Turn())
+			if err == nil {
+				fmt.Println("draw declined")
+			}
+		case "claim":
+			err = game.ClaimDraw()
+		default:
+			fmt.Printf("unknown draw command: %q\n", fields[1])
+			return false
+		}
+		if err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		if game.Completion.Done {
+			fmt.Println("game over:", game.Completion, game.Completion.Result())
+		}
+	case "undo", "redo":
+		n := 1
+		if len(fields) >= 2 {
+			num, err := strconv.Atoi(fields[1])
+			if err != nil || num < 1 {
+				fmt.Println("number of moves must be a positive number")
+				return false
+			}
+			n = num
+		}
+
+		if fields[0] == "undo" {
+			if err := game.Undo(n); err != nil {
+				fmt.Println("error:", err)
+				return false
+			}
+			for i := 0; i < n; i++ {
+				undone = append(undone, history[len(history)-1])
+				history = history[:len(history)-1]
+			}
+		} else {
+			if err := game.Redo(n); err != nil {
+				fmt.Println("error:", err)
+				return false
+			}
+			for i := 0; i < n; i++ {
+				history = append(history, undone[len(undone)-1])
+				undone = undone[:len(undone)-1]
+			}
+		}
 	case "pieces":
 		fmt.Println("white:")
 		for _, piece := range game.AlivePieces(chess.White) {
@@ -140,71 +277,24 @@ func runCmd(game *chess.Game, fields []string) bool {
 		fmt.Println("`print` deprecated, renamed to `board`")
 		fallthrough
 	case "board":
-		board := game.BoardRankFile()
+		rotated := game.Turn() == chess.Black
+		lines := boardLines(game.Board(), game.Duck, rotated)
 
-		var rotated bool
-		if game.Turn() == chess.Black {
-			board = rotate(board)
-			rotated = true
+		// show each player's time next to their side of the board
+		if clk != nil {
+			lines[1] += fmt.Sprintf("   %v %s", game.Turn().Other(), formatDuration(clk.Remaining(game.Turn().Other())))
+			lines[len(lines)-3] += fmt.Sprintf("   %v %s", game.Turn(), formatDuration(clk.Remaining(game.Turn())))
 		}
 
-		for rank := 7; rank >= 0; rank-- {
-			rankSlice := board[rank]
-			const black, white = 5, 15
-
-			fmt.Print("   ")
-
-			for file := 0; file < 8; file++ {
-				space := chess.Space{Rank: rank, File: file}
-				if space.Color() == chess.White {
-					fmt.Print(a.BgGray(white, "     "))
-				} else {
-					fmt.Print(a.BgGray(black, "     "))
-				}
-			}
-
-			fmt.Println()
-			if rotated {
-				fmt.Printf(" %d ", 8-rank)
-			} else {
-				fmt.Printf(" %d ", rank+1)
-			}
-
-			for file, piece := range rankSlice {
-				space := chess.Space{Rank: rank, File: file}
-
-				var symbol a.Value
-				if piece.Color == chess.White {
-					symbol = a.White(string(piece.Type.Symbol()))
-				} else {
-					symbol = a.Black(string(piece.Type.Symbol()))
-				}
-
-				if space.Color() == chess.White {
-					fmt.Print(a.Sprintf(a.BgGray(white, "  %s  "), a.BgGray(white, symbol)))
-				} else {
-					fmt.Print(a.Sprintf(a.BgGray(black, "  %s  "), a.BgGray(black, symbol)))
-				}
-			}
-
-			fmt.Println()
-			fmt.Print("   ")
-
-			for file := 0; file < 8; file++ {
-				space := chess.Space{Rank: rank, File: file}
-				if space.Color() == chess.White {
-					fmt.Print(a.BgGray(white, "     "))
-				} else {
-					fmt.Print(a.BgGray(black, "     "))
-				}
-			}
+		for _, line := range lines {
+			fmt.Println(line)
+		}
 
+		// show the pieces that each player can drop
+		if _, ok := game.Variant().(chess.Crazyhouse); ok {
 			fmt.Println()
-		}
-		if rotated {
-			fmt.Println("     h    g    f    e    d    c    b    a  ")
-		} else {
-			fmt.Println("     a    b    c    d    e    f    g    h  ")
+			fmt.Printf("   %v pocket: %s\n", chess.White, formatPocket(game.Pockets.White))
+			fmt.Printf("   %v pocket: %s\n", chess.Black, formatPocket(game.Pockets.Black))
 		}
 	case "fen":
 		all, err := ioutil.ReadAll(encoder.FENReader(game))
@@ -216,12 +306,103 @@ func runCmd(game *chess.Game, fields []string) bool {
 	case "pgn":
 		ch := make(chan chess.CompletionState, 1)
 		ch <- game.Completion
-		all, err := ioutil.ReadAll(encoder.PGNReader(nil, sliceToChan(history), ch))
+		tags := make(map[string]string)
+		if clk != nil {
+			tags["TimeControl"] = clk.Control().String()
+		}
+		if _, ok := game.Variant().(chess.Standard); !ok {
+			tags["Variant"] = game.Variant().Name()
+		} else if game.Chess960() {
+			tags["Variant"] = "Chess960"
+		}
+		if startFEN != "" {
+			tags["SetUp"] = "1"
+			tags["FEN"] = startFEN
+		}
+		all, err := ioutil.ReadAll(encoder.PGNReader(tags, sliceToChan(history), ch))
 		if err != nil {
 			fmt.Println("error:", err)
 			return false
 		}
 		fmt.Println(string(all))
+	case "clock":
+		if len(fields) < 2 {
+			if clk == nil {
+				fmt.Println("command clock:")
+				fmt.Println("\tstarts a clock for the game, or shows the remaining time if one is running")
+				fmt.Println("\tsyntax: clock [control [fischer|bronstein|delay]]")
+				fmt.Println("\tthe control uses the PGN TimeControl format, with times in seconds,")
+				fmt.Println("\tor in minutes with an m suffix. increments are always in seconds")
+				fmt.Println("\tex: `clock 300+3` (5 minutes with a 3 second increment)")
+				fmt.Println("\tex: `clock 40/5400+30:1800+30 bronstein`, or `clock 40/90m+30:30m+30 bronstein`")
+				return false
+			}
+			if clk.CheckFlag() {
+				fmt.Println("game over:", game.Completion, game.Completion.Result())
+			}
+			fmt.Printf("white %s, black %s\n", formatDuration(clk.Remaining(chess.White)), formatDuration(clk.Remaining(chess.Black)))
+			return true
+		}
+
+		control, err := clock.ParseControl(fields[1])
+		if err != nil {
+			fmt.Println("error:", err)
+			return false
+		}
+		if len(fields) >= 3 {
+			switch fields[2] {
+			case "fischer":
+				control.Method = clock.Fischer
+			case "bronstein":
+				control.Method = clock.Bronstein
+			case "delay":
+				control.Method = clock.SimpleDelay
+			default:
+				fmt.Printf("unknown clock method: %q\n", fields[2])
+				return false
+			}
+		}
+		clk = clock.New(game, control)
+		clk.Start()
+	case "chess960":
+		if len(fields) >= 2 {
+			id, err := strconv.Atoi(fields[1])
+			if err != nil || id < 0 || id >= 960 {
+				fmt.Println("position must be a number between 0 and 959")
+				return false
+			}
+			game.InitChess960(id)
+		} else {
+			fmt.Println("starting position", game.InitChess960Random())
+		}
+		newGame(game)
+	case "bughouse":
+		match = &chess.BughouseMatch{}
+		match.Init()
+		fmt.Println("started a bughouse match, run `exit` to go back to the last game")
+	case "kriegspiel":
+		kriegspiel = &chess.Kriegspiel{}
+		kriegspiel.Init()
+		fmt.Println("started a kriegspiel game, run `exit` to go back to the last game")
+		fmt.Println("white moves first, and `board` only shows the pieces of the player to move")
+	case "variant":
+		if len(fields) < 2 {
+			fmt.Println("current variant:", game.Variant().Name())
+			fmt.Print("available variants:")
+			for name := range variants {
+				fmt.Print(" ", name)
+			}
+			fmt.Println()
+			return true
+		}
+
+		variant, ok := variants[fields[1]]
+		if !ok {
+			fmt.Printf("unknown variant: %q\n", fields[1])
+			return false
+		}
+		game.InitVariant(variant)
+		newGame(game)
 	case "stockfish":
 		difficulty := 20
 		if len(fields) >= 3 {
@@ -240,7 +421,7 @@ func runCmd(game *chess.Game, fields []string) bool {
 		}
 
 		fmt.Println("running stockfish...")
-		sfSuggest, err := runStockfish(string(fen), difficulty)
+		sfSuggest, err := runStockfish(string(fen), difficulty, uciVariant(game.Variant()), game.Chess960())
 
 		if len(fields) >= 2 && fields[1] == "move" {
 			fmt.Println("stockfish plays " + sfSuggest)
@@ -260,9 +441,27 @@ func runCmd(game *chess.Game, fields []string) bool {
 		fmt.Println("move <algebraic>")
 		fmt.Println("\tmakes a move using algebraic notation")
 		fmt.Println("\tex: `move e4`, `move a8Q`, `move Raxd1")
+		fmt.Println("\tin crazyhouse, pieces are dropped with `move N@f3`")
+		fmt.Println("\tin duck chess, the duck's space follows a comma, such as `move e4,e5`")
 		fmt.Println("\tmore information about algebraic notation:")
 		fmt.Println("\thttps://en.wikipedia.org/wiki/Algebraic_notation_(chess)")
 		fmt.Println()
+		fmt.Println("resign")
+		fmt.Println("\tthe current player resigns the game")
+		fmt.Println()
+		fmt.Println("draw <offer|accept|decline|claim>")
+		fmt.Println("\toffers, accepts, or declines a draw, or claims")
+		fmt.Println("\ta draw by the 50 move rule or threefold repetition")
+		fmt.Println()
+		fmt.Println("undo [n=1]")
+		fmt.Println("\ttakes back the last n moves")
+		fmt.Println()
+		fmt.Println("redo [n=1]")
+		fmt.Println("\tmakes the last n moves taken back by undo again")
+		fmt.Println()
+		fmt.Println("moves")
+		fmt.Println("\tlists the legal moves for the current player")
+		fmt.Println()
 		fmt.Println("pieces")
 		fmt.Println("\tlists remaining pieces in the game")
 		fmt.Println()
@@ -275,6 +474,25 @@ func runCmd(game *chess.Game, fields []string) bool {
 		fmt.Println("board")
 		fmt.Println("\toutputs the game on a human-readable board")
 		fmt.Println()
+		fmt.Println("bughouse")
+		fmt.Println("\tstarts a bughouse match on two boards side by side")
+		fmt.Println()
+		fmt.Println("kriegspiel")
+		fmt.Println("\tstarts a hot-seat kriegspiel game, where each player")
+		fmt.Println("\tonly sees their own pieces")
+		fmt.Println()
+		fmt.Println("variant [name]")
+		fmt.Println("\tstarts a new game of the variant, or lists the variants")
+		fmt.Println("\tex: `variant kingofthehill`")
+		fmt.Println()
+		fmt.Println("chess960 [id]")
+		fmt.Println("\tstarts a new Chess960 game from the numbered start position,")
+		fmt.Println("\tor a random one if no number is given")
+		fmt.Println()
+		fmt.Println("clock [control [fischer|bronstein|delay]]")
+		fmt.Println("\tstarts a clock using a PGN TimeControl, such as 300+3,")
+		fmt.Println("\tor shows the remaining time if a clock is running")
+		fmt.Println()
 		fmt.Println("stockfish [move [difficulty=20]]")
 		fmt.Println("\thas stockfish suggest a move. if `move` is")
 		fmt.Println("\tset, stockfish will make the move as well")
@@ -287,20 +505,116 @@ func runCmd(game *chess.Game, fields []string) bool {
 	return true
 }
 
+// newGame forgets the moves and clock of the last game after
+// game has been set up for a new one.
+func newGame(game *chess.Game) {
+	history, undone, clk = nil, nil, nil
+
+	fen, err := ioutil.ReadAll(encoder.FENReader(game))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	startFEN = ""
+	if string(fen) != classicFEN {
+		startFEN = string(fen)
+	}
+}
+
+// formatDuration formats d as h:mm:ss, or m:ss if it is under an hour.
+func formatDuration(d time.Duration) string {
+	seconds := int(d / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// formatPocket returns the pieces in a pocket, such as "♛ ♟x2", or "empty".
+// Registered fairy pieces are listed after the knights.
+func formatPocket(pocket chess.Pocket) string {
+	var parts []string
+	order := append([]chess.PieceType{chess.PieceQueen, chess.PieceRook, chess.PieceBishop, chess.PieceKnight}, chess.FairyPieces()...)
+	for _, t := range append(order, chess.PiecePawn) {
+		switch n := pocket[t]; {
+		case n == 1:
+			parts = append(parts, string(t.Symbol()))
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%cx%d", t.Symbol(), n))
+		}
+	}
+	if len(parts) == 0 {
+		return "empty"
+	}
+	return strings.Join(parts, " ")
+}
+
 func sliceToChan(moves []chess.Move) <-chan chess.Move {
 	ch := make(chan chess.Move, len(moves))
 	for _, elem := range moves {
 		ch <- elem
 	}
+	close(ch)
 	return ch
 }
 
-func rotate(board [8][8]chess.Piece) [8][8]chess.Piece {
-	var newBoard [8][8]chess.Piece
-	for i, rank := range board {
-		for j := range rank {
-			newBoard[7-i][7-j] = board[i][j]
+// boardLines returns the lines of board, in [file][rank] form, as it is
+// printed, with black at the bottom if rotated, and the duck of Duck Chess
+// on its space if there is one. Each rank is three lines tall, with the
+// pieces on the middle line, and the last line labels the files.
+func boardLines(board [][]chess.Piece, duck chess.Bitboard, rotated bool) []string {
+	files, ranks := len(board), len(board[0])
+
+	const black, white = 5, 15
+	background := func(space chess.Space) uint8 {
+		if space.Color() == chess.White {
+			return white
 		}
+		return black
+	}
+
+	// the rank or file shown at index i of a row or column
+	// of the board, which is turned around if rotated
+	shown := func(i, n int, reversed bool) int {
+		if reversed {
+			return n - 1 - i
+		}
+		return i
+	}
+
+	var lines []string
+	for row := 0; row < ranks; row++ {
+		rank := shown(row, ranks, !rotated)
+
+		var empty, pieces strings.Builder
+		empty.WriteString("   ")
+		fmt.Fprintf(&pieces, "%2d ", rank+1)
+
+		for column := 0; column < files; column++ {
+			file := shown(column, files, rotated)
+			piece := board[file][rank]
+			bg := background(chess.Space{Rank: rank, File: file})
+
+			var symbol a.Value
+			if duck.Has(chess.Space{Rank: rank, File: file}) {
+				symbol = a.Yellow("D")
+			} else if piece.Color == chess.White {
+				symbol = a.White(string(piece.Type.Symbol()))
+			} else {
+				symbol = a.Black(string(piece.Type.Symbol()))
+			}
+
+			empty.WriteString(a.BgGray(bg, "     ").String())
+			pieces.WriteString(a.Sprintf(a.BgGray(bg, "  %s  "), a.BgGray(bg, symbol)))
+		}
+
+		lines = append(lines, empty.String(), pieces.String(), empty.String())
+	}
+
+	var labels strings.Builder
+	labels.WriteString("   ")
+	for column := 0; column < files; column++ {
+		fmt.Fprintf(&labels, "  %c  ", 'a'+shown(column, files, rotated))
 	}
-	return newBoard
+	return append(lines, labels.String())
 }
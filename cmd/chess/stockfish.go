@@ -9,8 +9,9 @@ import (
 	"golang.org/x/xerrors"
 )
 
-// takes a FEN and returns the UCI for the best move
-func runStockfish(fen string, difficulty int) (string, error) {
+// takes a FEN and returns the UCI for the best move. variants other than
+// "chess" need a build of stockfish which supports UCI_Variant.
+func runStockfish(fen string, difficulty int, variant string, chess960 bool) (string, error) {
 	cmd := exec.Command("stockfish")
 
 	in, _ := cmd.StdinPipe()
@@ -20,6 +21,12 @@ func runStockfish(fen string, difficulty int) (string, error) {
 	defer cmd.Process.Release()
 
 	in.Write([]byte("setoption name Skill Level value " + strconv.Itoa(difficulty) + "\n"))
+	if variant != "chess" {
+		in.Write([]byte("setoption name UCI_Variant value " + variant + "\n"))
+	}
+	if chess960 {
+		in.Write([]byte("setoption name UCI_Chess960 value true\n"))
+	}
 	in.Write([]byte("position fen " + fen + "\n"))
 	in.Write([]byte("go movetime 3000\n"))
 
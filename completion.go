@@ -0,0 +1,88 @@
+package chess
+
+// CompletionState represents the current completion state of the board
+type CompletionState struct {
+	Done   bool
+	Draw   bool
+	Winner Color
+
+	// why the game ended, if it is done
+	Reason Termination
+}
+
+// Result returns the result of the game as written in PGN:
+// "1-0" if white won, "0-1" if black won, "1/2-1/2" for
+// a draw, or "*" if the game is still in progress.
+func (c CompletionState) Result() string {
+	switch {
+	case !c.Done:
+		return "*"
+	case c.Draw:
+		return "1/2-1/2"
+	case c.Winner == White:
+		return "1-0"
+	default:
+		return "0-1"
+	}
+}
+
+func (c CompletionState) String() string {
+	switch {
+	case !c.Done:
+		return "in progress"
+	case c.Draw:
+		return "draw by " + c.Reason.String()
+	default:
+		return c.Winner.String() + " wins by " + c.Reason.String()
+	}
+}
+
+// Termination represents the reason that a game ended.
+type Termination byte
+
+// The enum of terminations
+const (
+	TerminationNone Termination = iota
+	TerminationCheckmate
+	TerminationStalemate
+	TerminationResignation
+	TerminationTimeout
+	TerminationAgreement
+	TerminationFiftyMoveRule
+	TerminationSeventyFiveMoveRule
+	TerminationThreefoldRepetition
+	TerminationFivefoldRepetition
+	TerminationInsufficientMaterial
+	TerminationTimeoutVsInsufficientMaterial
+	TerminationKingOfTheHill
+	TerminationThreeCheck
+	TerminationExplosion
+	TerminationNoMoves
+	TerminationHordeCaptured
+	TerminationEighthRank
+	TerminationKingCaptured
+)
+
+func (t Termination) String() string {
+	return [...]string{
+		"none",
+		"checkmate",
+		"stalemate",
+		"resignation",
+		"timeout",
+		"agreement",
+		"fifty-move rule",
+		"seventy-five-move rule",
+		"threefold repetition",
+		"fivefold repetition",
+		"insufficient material",
+		"timeout vs insufficient material",
+		"king in the center",
+		"three checks",
+		"king explosion",
+		"running out of moves",
+		"capturing the horde",
+		"king reaching the eighth rank",
+		"capturing the king",
+	}[t]
+}
@@ -0,0 +1,190 @@
+package chess
+
+import (
+	"errors"
+	"strings"
+)
+
+// Crazyhouse is the Variant where captured pieces go to the capturing
+// player's pocket, and may be dropped back onto the board as their move.
+// Promoted pieces go back to being pawns when they are captured.
+//
+// In FEN, the pockets are written in brackets after the board, such as
+// "[Qnp]", and promoted pieces on the board are followed by a "~".
+type Crazyhouse struct {
+	Standard
+}
+
+// Name returns "Crazyhouse".
+func (Crazyhouse) Name() string {
+	return "Crazyhouse"
+}
+
+// Moves appends all of c's legal moves to moves, including drops.
+func (Crazyhouse) Moves(g *Game, c Color, moves []Move) []Move {
+	moves = Standard{}.Moves(g, c, moves)
+	for _, mv := range g.dropMoves(c, nil) {
+		moves = append(moves, g.moveOf(mv))
+	}
+	return moves
+}
+
+// dropsCaptures marks that captured pieces are dropped back onto the
+// board, so positions from before a capture may be repeated.
+func (Crazyhouse) dropsCaptures() {}
+
+// AfterMove puts the piece captured by m into the pocket of m's player,
+// and keeps track of which pieces have been promoted. In Chess960, a king
+// may castle onto its own rook's space, which does not capture the rook.
+func (Crazyhouse) AfterMove(g *Game, m Move, captured PieceType) {
+	// g.Promoted has not been updated for m yet
+	if captured != PieceNone {
+		pocket := g.Pockets.Of(m.Moving.Color)
+		if g.Promoted.Has(m.To) {
+			pocket[PiecePawn]++
+		} else {
+			pocket[captured]++
+		}
+	}
+
+	g.Promoted = movePromoted(g.Promoted, m)
+}
+
+// movePromoted returns promoted after m is made, so that
+// promoted pieces keep being promoted as they move.
+func movePromoted(promoted Bitboard, m Move) Bitboard {
+	if m.Drop || m.Castle {
+		return promoted
+	}
+
+	from, to := squareOf(m.Moving.Location), squareOf(m.To)
+	wasPromoted := promoted&squareBit(from) != 0
+	promoted &^= squareBit(from) | squareBit(to)
+	if wasPromoted || m.Promotion != PieceNone {
+		promoted |= squareBit(to)
+	}
+	return promoted
+}
+
+// Completion ends the game by the standard rules, except for insufficient
+// material, since pieces can always be dropped back onto the board.
+func (Crazyhouse) Completion(g *Game) CompletionState {
+	switch {
+	case g.InCheckmate(g.Turn()):
+		return CompletionState{Done: true, Winner: g.Turn().Other(), Reason: TerminationCheckmate}
+	case g.InStalemate(g.Turn()):
+		return CompletionState{Done: true, Draw: true, Reason: TerminationStalemate}
+	}
+	return g.automaticDraw()
+}
+
+// ReadFEN reads the pockets and promoted pieces from the board state.
+func (Crazyhouse) ReadFEN(g *Game, fields []string) error {
+	if len(fields) != 6 {
+		return errors.New("expected 6 fields")
+	}
+	return readPockets(g, fields[0])
+}
+
+// WriteFEN adds the pockets and promoted pieces to the board state.
+func (Crazyhouse) WriteFEN(g *Game, fields []string) []string {
+	fields[0] = writePockets(g, fields[0])
+	return fields
+}
+
+// readPockets reads the pockets from the brackets after a FEN's board
+// state, and the promoted pieces from the "~" after them on the board.
+// The pockets are empty if the board state does not have brackets.
+func readPockets(g *Game, board string) error {
+	var pocket string
+	start := strings.IndexByte(board, '[')
+	if start == -1 {
+		start = len(board)
+	} else if strings.HasSuffix(board, "]") {
+		pocket = board[start+1 : len(board)-1]
+	} else {
+		return errors.New("expected pockets to end with ]")
+	}
+
+	g.Pockets = pockets{}
+	for _, char := range []byte(pocket) {
+		t, c, ok := pieceOfChar(char)
+		if !ok || t == PieceKing {
+			return errors.New("invalid piece in pocket " + string(char))
+		}
+		g.Pockets.Of(c)[t]++
+	}
+
+	g.Promoted = 0
+	sq := 56
+	for _, char := range []byte(board[:start]) {
+		switch {
+		case char == '/':
+			sq -= 16
+		case char >= '1' && char <= '8':
+			sq += int(char - '0')
+		case char == '~':
+			if sq == 0 || g.mailbox[sq-1] == PieceNone {
+				return errors.New("promoted marker must follow a piece")
+			}
+			g.Promoted |= squareBit(sq - 1)
+		default:
+			sq++
+		}
+	}
+	return nil
+}
+
+// writePockets adds the pockets and promoted pieces to a FEN's board state.
+func writePockets(g *Game, board string) string {
+	var builder strings.Builder
+
+	sq := 56
+	for _, char := range []byte(board) {
+		builder.WriteByte(char)
+		switch {
+		case char == '/':
+			sq -= 16
+		case char >= '1' && char <= '8':
+			sq += int(char - '0')
+		default:
+			if g.Promoted&squareBit(sq) != 0 {
+				builder.WriteByte('~')
+			}
+			sq++
+		}
+	}
+
+	builder.WriteByte('[')
+	for _, c := range [...]Color{White, Black} {
+		pocket := g.Pockets.Of(c)
+		order := append([]PieceType{PieceQueen, PieceRook, PieceBishop, PieceKnight}, FairyPieces()...)
+		for _, t := range append(order, PiecePawn) {
+			for i := 0; i < pocket[t]; i++ {
+				builder.WriteByte(charOfPiece(t, c))
+			}
+		}
+	}
+	builder.WriteByte(']')
+
+	return builder.String()
+}
+
+// pieceOfChar returns the piece type and color of a FEN piece character,
+// which is uppercase for white and lowercase for black.
+func pieceOfChar(char byte) (PieceType, Color, bool) {
+	c := White
+	if char >= 'a' && char <= 'z' {
+		c = Black
+	}
+	t, ok := ParsePieceType(char)
+	return t, c, ok
+}
+
+// charOfPiece returns the FEN piece character of t and c.
+func charOfPiece(t PieceType, c Color) byte {
+	if c == Black {
+		return t.ShortName() - 'A' + 'a'
+	}
+	return t.ShortName()
+}
@@ -0,0 +1,117 @@
+package chess
+
+// Resign ends the game with c resigning, so that c's opponent wins.
+func (g *Game) Resign(c Color) error {
+	if g.Completion.Done {
+		return ErrGameOver
+	}
+
+	g.Completion = CompletionState{Done: true, Winner: c.Other(), Reason: TerminationResignation}
+	g.drawOffered = false
+	return nil
+}
+
+// Timeout ends the game with c running out of time. c's opponent
+// wins, unless they cannot checkmate by any series of legal moves,
+// in which case the game is drawn.
+func (g *Game) Timeout(c Color) error {
+	if g.Completion.Done {
+		return ErrGameOver
+	}
+
+	if !g.canCheckmate(c.Other()) {
+		g.Completion = CompletionState{Done: true, Draw: true, Reason: TerminationTimeoutVsInsufficientMaterial}
+	} else {
+		g.Completion = CompletionState{Done: true, Winner: c.Other(), Reason: TerminationTimeout}
+	}
+	g.drawOffered = false
+	return nil
+}
+
+// OfferDraw offers a draw on behalf of c. The offer stands until the
+// opponent accepts it, declines it, or declines it by making a move.
+// Offering a draw while the opponent's offer stands accepts it.
+func (g *Game) OfferDraw(c Color) error {
+	if g.Completion.Done {
+		return ErrGameOver
+	}
+
+	if g.drawOffered && g.drawOfferedBy != c {
+		return g.AcceptDraw(c)
+	}
+
+	g.drawOffered = true
+	g.drawOfferedBy = c
+	return nil
+}
+
+// DrawOffer returns who offered the standing draw
+// offer, and if there is a standing offer at all.
+func (g *Game) DrawOffer() (Color, bool) {
+	return g.drawOfferedBy, g.drawOffered
+}
+
+// AcceptDraw accepts the standing draw offer on behalf of c, ending
+// the game. c may not accept their own offer.
+func (g *Game) AcceptDraw(c Color) error {
+	if g.Completion.Done {
+		return ErrGameOver
+	}
+	if !g.drawOffered {
+		return ErrNoDrawOffer
+	}
+	if g.drawOfferedBy == c {
+		return ErrOwnDrawOffer
+	}
+
+	g.Completion = CompletionState{Done: true, Draw: true, Reason: TerminationAgreement}
+	g.drawOffered = false
+	return nil
+}
+
+// DeclineDraw declines the standing draw offer on behalf of c.
+// c may not decline their own offer.
+func (g *Game) DeclineDraw(c Color) error {
+	if g.Completion.Done {
+		return ErrGameOver
+	}
+	if !g.drawOffered {
+		return ErrNoDrawOffer
+	}
+	if g.drawOfferedBy == c {
+		return ErrOwnDrawOffer
+	}
+
+	g.drawOffered = false
+	return nil
+}
+
+// ClaimDraw ends the game in a draw if the 50 move rule or
+// threefold repetition currently allows a draw to be claimed.
+func (g *Game) ClaimDraw() error {
+	if g.Completion.Done {
+		return ErrGameOver
+	}
+
+	reason := g.claimableDraw()
+	if reason == TerminationNone {
+		return ErrCannotClaimDraw
+	}
+
+	g.Completion = CompletionState{Done: true, Draw: true, Reason: reason}
+	g.drawOffered = false
+	return nil
+}
+
+// claimableDraw returns the reason that a draw can be
+// claimed, or TerminationNone if one cannot be claimed.
+func (g *Game) claimableDraw() Termination {
+	// 50 moves by each player
+	if g.Halfmove >= 100 {
+		return TerminationFiftyMoveRule
+	}
+	if g.RepetitionCount() >= 3 {
+		return TerminationThreefoldRepetition
+	}
+	return TerminationNone
+}
@@ -0,0 +1,48 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/deanveloper/chess"
+	"github.com/deanveloper/chess/encoder"
+)
+
+func TestDrawOffer(t *testing.T) {
+	game := &chess.Game{}
+	game.InitClassic()
+
+	if err := game.OfferDraw(chess.White); err != nil {
+		t.Fatal(err)
+	}
+	if err := game.AcceptDraw(chess.White); err != chess.ErrOwnDrawOffer {
+		t.Errorf("white accepting their own offer = %v, want %v", err, chess.ErrOwnDrawOffer)
+	}
+	if err := game.DeclineDraw(chess.White); err != chess.ErrOwnDrawOffer {
+		t.Errorf("white declining their own offer = %v, want %v", err, chess.ErrOwnDrawOffer)
+	}
+
+	// taking back a move withdraws the offer
+	move, err := encoder.FromAlgebraic(game, "e4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	if err := game.Undo(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, offered := game.DrawOffer(); offered {
+		t.Error("draw offer stands after undo")
+	}
+
+	if err := game.OfferDraw(chess.White); err != nil {
+		t.Fatal(err)
+	}
+	if err := game.AcceptDraw(chess.Black); err != nil {
+		t.Fatal(err)
+	}
+	if !game.Completion.Draw || game.Completion.Reason != chess.TerminationAgreement {
+		t.Errorf("completion = %v, want a draw by agreement", game.Completion)
+	}
+}
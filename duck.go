@@ -0,0 +1,129 @@
+package chess
+
+import (
+	"errors"
+	"strings"
+)
+
+// Duck is the Variant for Duck Chess, where every move is made in two
+// parts: a normal move, and then moving the duck to a different empty
+// space. The duck belongs to neither player, and no piece may move onto,
+// capture or move through it. There is no check, so a player wins by
+// capturing the other player's king, or by having no legal moves.
+//
+// The duck is not on the board until after white's first move. In FEN,
+// its space on the board is written as "*", and in algebraic notation
+// its space follows the rest of the move after a comma, such as "e4,e5".
+type Duck struct {
+	Standard
+}
+
+// Name returns "Duck Chess".
+func (Duck) Name() string {
+	return "Duck Chess"
+}
+
+// Moves appends all of c's legal moves to moves. Each move has a copy for
+// every space that the duck may move to afterwards, so there are usually
+// a few dozen moves for each way that c's pieces may move.
+func (Duck) Moves(g *Game, c Color, moves []Move) []Move {
+	if duckResult(g).Done {
+		return moves
+	}
+
+	var candidates []move
+	for own := g.colors[colorIndex(c)]; own != 0; {
+		from := own.pop()
+		candidates = g.pseudoMovesFrom(from, promotions[:], candidates)
+
+		// kings may castle out of and through check,
+		// as long as the duck is not in the way
+		for _, kingSide := range [...]bool{true, false} {
+			kingFrom, kingTo, _, _ := g.castleSquares(c, kingSide)
+			if kingFrom == from && g.canCastle(c, kingSide) {
+				candidates = append(candidates, move{from: kingFrom, to: kingTo, castle: true})
+			}
+		}
+	}
+
+	for _, mv := range candidates {
+		for empty := duckSpaces(g, c, mv); empty != 0; {
+			mv.duck = squareBit(empty.pop())
+			moves = append(moves, g.moveOf(mv))
+		}
+	}
+	return moves
+}
+
+// duckSpaces returns the spaces that the duck may move to after c makes
+// mv, which are the spaces that are empty after mv other than the duck's.
+func duckSpaces(g *Game, c Color, mv move) Bitboard {
+	vacated, filled := squareBit(mv.from), squareBit(mv.to)
+	if mv.enPassant {
+		vacated |= squareBit(g.enPassantVictim(mv))
+	}
+	if mv.castle {
+		rookFrom, rookTo := g.castleRook(c, mv.to)
+		vacated |= squareBit(rookFrom)
+		filled |= squareBit(rookTo)
+	}
+	return (^g.occupied() | vacated) &^ filled
+}
+
+// InCheck returns false, as there is no check in Duck Chess.
+func (Duck) InCheck(g *Game, c Color) bool {
+	return false
+}
+
+// Completion ends the game once a king has been captured, or once the
+// player to move has no legal moves, in which case that player wins. The
+// game is not drawn by insufficient material.
+func (Duck) Completion(g *Game) CompletionState {
+	if result := duckResult(g); result.Done {
+		return result
+	}
+	if !g.canMove(g.Turn()) {
+		return CompletionState{Done: true, Winner: g.Turn(), Reason: TerminationNoMoves}
+	}
+	return g.automaticDraw()
+}
+
+// duckResult returns the completion state of g if a king has been captured.
+func duckResult(g *Game) CompletionState {
+	for _, c := range [...]Color{White, Black} {
+		if g.Bitboard(c, PieceKing) == 0 {
+			return CompletionState{Done: true, Winner: c.Other(), Reason: TerminationKingCaptured}
+		}
+	}
+	return CompletionState{}
+}
+
+// ReadFEN reads the space of the duck from the "*" in the board state.
+func (Duck) ReadFEN(g *Game, fields []string) error {
+	if len(fields) != 6 {
+		return errors.New("expected 6 fields")
+	}
+	if strings.ContainsAny(fields[0], "[~") {
+		return errors.New("unexpected pocket or promoted piece in board state")
+	}
+
+	g.Duck = 0
+	sq := 56
+	for _, char := range []byte(fields[0]) {
+		switch {
+		case char == '/':
+			sq -= 16
+		case char >= '1' && char <= '8':
+			sq += int(char - '0')
+		case char == '*':
+			if g.Duck != 0 {
+				return errors.New("more than one duck in board state")
+			}
+			g.Duck = squareBit(sq)
+			sq++
+		default:
+			sq++
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package encoder
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/deanveloper/chess"
@@ -19,6 +20,21 @@ func (a algebraicError) Error() string {
 // FromAlgebraic returns a move from an algebraic string
 func FromAlgebraic(g *chess.Game, algebraic string) (chess.Move, error) {
 
+	// in Duck Chess, the space that the duck moves to
+	// follows the rest of the move after a comma
+	var duck chess.Space
+	_, ducks := g.Variant().(chess.Duck)
+	if i := strings.IndexByte(algebraic, ','); i >= 0 {
+		space, ok := parseSpace(algebraic[i+1:])
+		if !ok || !ducks || !g.OnBoard(space) {
+			return chess.Move{}, algebraicError{algebraic: algebraic, reason: "invalid duck space " + algebraic[i+1:]}
+		}
+		duck = space
+		algebraic = algebraic[:i]
+	} else if ducks {
+		return chess.Move{}, algebraicError{algebraic: algebraic, reason: "must specify where to move the duck"}
+	}
+
 	// remove check(mate) and en passant symbols
 	algebraic = strings.TrimSuffix(algebraic, "+")
 	algebraic = strings.TrimSuffix(algebraic, "+")
@@ -31,20 +47,15 @@ func FromAlgebraic(g *chess.Game, algebraic string) (chess.Move, error) {
 
 	// handle castles
 	if algebraic == "O-O" || algebraic == "0-0" {
-		king := g.TypedAlivePieces(g.Turn(), chess.PieceKing)[0]
-		return chess.Move{
-			Snapshot: *g,
-			Moving:   king,
-			To:       chess.Space{File: king.Location.File + 2, Rank: king.Location.Rank},
-		}, nil
+		return findCastle(g, algebraic, true, duck)
 	}
 	if algebraic == "O-O-O" || algebraic == "0-0-0" {
-		king := g.TypedAlivePieces(g.Turn(), chess.PieceKing)[0]
-		return chess.Move{
-			Snapshot: *g,
-			Moving:   king,
-			To:       chess.Space{File: king.Location.File - 2, Rank: king.Location.Rank},
-		}, nil
+		return findCastle(g, algebraic, false, duck)
+	}
+
+	// handle drops, such as "N@f3", or "@e4" for pawns
+	if i := strings.IndexByte(algebraic, '@'); i >= 0 {
+		return findDrop(g, algebraic, i)
 	}
 
 	var promotion chess.PieceType
@@ -61,18 +72,36 @@ func FromAlgebraic(g *chess.Game, algebraic string) (chess.Move, error) {
 	case 'Q':
 		promotion = chess.PieceQueen
 		algebraic = algebraic[:len(algebraic)-1]
+	case 'K':
+		// only in variants such as Antichess
+		promotion = chess.PieceKing
+		algebraic = algebraic[:len(algebraic)-1]
+	default:
+		if t, ok := fairyPieceType(algebraic[len(algebraic)-1]); ok {
+			promotion = t
+			algebraic = algebraic[:len(algebraic)-1]
+		}
 	}
+	algebraic = strings.TrimSuffix(algebraic, "=")
 
-	target := chess.Space{
-		File: int(algebraic[len(algebraic)-2] - 'a'),
-		Rank: int(algebraic[len(algebraic)-1] - '1'),
+	if len(algebraic) < 2 {
+		return chess.Move{}, algebraicError{algebraic: algebraic, reason: "too short"}
 	}
-	if !target.Valid() {
+
+	// the target square is at the end, and may have a two digit
+	// rank on boards with more than nine ranks, such as "j10"
+	at := len(algebraic) - 1
+	for at > 0 && algebraic[at] >= '0' && algebraic[at] <= '9' {
+		at--
+	}
+	target, ok := parseSpace(algebraic[at:])
+	if !ok || !g.OnBoard(target) {
 		return chess.Move{}, algebraicError{
 			algebraic: algebraic,
-			reason:    "invalid target square " + algebraic[len(algebraic)-2:],
+			reason:    "invalid target square " + algebraic[at:],
 		}
 	}
+	prefix := algebraic[:at]
 
 	var pieceType chess.PieceType
 	switch algebraic[0] {
@@ -88,59 +117,65 @@ func FromAlgebraic(g *chess.Game, algebraic string) (chess.Move, error) {
 		pieceType = chess.PieceKing
 	default:
 		pieceType = chess.PiecePawn
+		if t, ok := fairyPieceType(algebraic[0]); ok {
+			pieceType = t
+		}
 	}
 
+	// the piece may be disambiguated by the
+	// file, rank, or space that it moves from
 	file := -1
 	rank := -1
-	if len(algebraic) > 3 {
-		char := algebraic[1]
-		if pieceType == chess.PiecePawn {
-			char = algebraic[0]
+	disambiguation := strings.TrimSuffix(prefix, "x")
+	if pieceType != chess.PiecePawn {
+		disambiguation = disambiguation[1:]
+	}
+	if len(disambiguation) > 0 && disambiguation[0] >= 'a' && disambiguation[0] <= 'z' {
+		file = int(disambiguation[0] - 'a')
+		disambiguation = disambiguation[1:]
+	}
+	if n, err := strconv.Atoi(disambiguation); err == nil && n > 0 {
+		rank = n - 1
+	}
+
+	var moveFound bool
+	var move chess.Move
+	for _, each := range g.LegalMoves() {
+		if each.Drop || each.Moving.Type != pieceType || each.To != target || each.Promotion != promotion || each.Duck != duck {
+			continue
 		}
-		if char >= 'a' && char <= 'h' {
-			file = int(char - 'a')
+		if file >= 0 && file != each.Moving.Location.File {
+			continue
 		}
-		if char >= '1' && char <= '8' {
-			rank = int(char - '1')
+		if rank >= 0 && rank != each.Moving.Location.Rank {
+			continue
 		}
-	}
-
-	var pieceFound bool
-	var piece chess.Piece
-	for _, eachPiece := range g.TypedAlivePieces(g.Turn(), pieceType) {
-		for _, space := range eachPiece.Seeing() {
-			if space == target {
-				if file >= 0 && file != eachPiece.Location.File {
-					continue
-				}
-				if rank >= 0 && rank != eachPiece.Location.Rank {
-					continue
-				}
-				if pieceFound {
-					return chess.Move{}, algebraicError{
-						algebraic: algebraic,
-						reason:    "move is ambiguous",
-					}
-				}
-				piece = eachPiece
-				pieceFound = true
+		if moveFound {
+			return chess.Move{}, algebraicError{
+				algebraic: algebraic,
+				reason:    "move is ambiguous",
 			}
 		}
+		move = each
+		moveFound = true
 	}
 
-	if !pieceFound {
+	if !moveFound {
+		reason := "could not find a " + pieceType.String() + " which can legally move to " + target.String()
+		if _, ranks := g.Size(); pieceType == chess.PiecePawn && promotion == chess.PieceNone && (target.Rank == 0 || target.Rank == ranks-1) {
+			reason = "must specify what to promote pawn to"
+		}
 		return chess.Move{}, algebraicError{
 			algebraic: algebraic,
-			reason:    "could not find a " + pieceType.String() + " targetting " + target.String(),
+			reason:    reason,
 		}
 	}
 
-	// if a piece is being captured, an x must appear as the 2nd or 3rd character
-	algCapturing := algebraic[1] == 'x' || (len(algebraic) > 2 && algebraic[2] == 'x')
+	// if a piece is being captured, an x must appear right before the target
+	algCapturing := strings.HasSuffix(prefix, "x")
 
-	targetPiece, _ := g.PieceAt(target)
-	actCapturing := targetPiece.Type != chess.PieceNone && targetPiece.Color != g.Turn()
-	if pieceType == chess.PiecePawn && target == g.EnPassant {
+	_, actCapturing := g.PieceAt(target)
+	if move.EnPassant {
 		actCapturing = true
 	}
 
@@ -151,69 +186,143 @@ func FromAlgebraic(g *chess.Game, algebraic string) (chess.Move, error) {
 		}
 	}
 
-	return chess.Move{
-		Snapshot:  *g,
-		Moving:    piece,
-		To:        target,
-		Promotion: promotion,
-	}, nil
+	return move, nil
+}
+
+// findCastle finds the legal castle for the current player on the king's
+// side if kingSide is true, or the queen's side otherwise. In Duck Chess,
+// the duck moves to duck afterwards.
+func findCastle(g *chess.Game, algebraic string, kingSide bool, duck chess.Space) (chess.Move, error) {
+	files, _ := g.Size()
+	for _, move := range g.LegalMoves() {
+		if !move.Castle || move.Duck != duck {
+			continue
+		}
+		// the king always castles to the second file from the edge,
+		// which is the g-file or c-file on an 8x8 board
+		if (move.To.File == files-2) == kingSide {
+			return move, nil
+		}
+	}
+	return chess.Move{}, algebraicError{
+		algebraic: algebraic,
+		reason:    "cannot castle",
+	}
+}
+
+// findDrop finds the legal drop for the current player, where
+// the '@' in the algebraic string is at index at.
+func findDrop(g *chess.Game, algebraic string, at int) (chess.Move, error) {
+	pieceType := chess.PiecePawn
+	switch algebraic[:at] {
+	case "", "P":
+	case "R":
+		pieceType = chess.PieceRook
+	case "N":
+		pieceType = chess.PieceKnight
+	case "B":
+		pieceType = chess.PieceBishop
+	case "Q":
+		pieceType = chess.PieceQueen
+	default:
+		t, ok := fairyPieceType(algebraic[0])
+		if !ok || at != 1 {
+			return chess.Move{}, algebraicError{algebraic: algebraic, reason: "invalid piece to drop " + algebraic[:at]}
+		}
+		pieceType = t
+	}
+
+	target := algebraic[at+1:]
+	to, ok := parseSpace(target)
+	if !ok || !g.OnBoard(to) {
+		return chess.Move{}, algebraicError{algebraic: algebraic, reason: "invalid target square " + target}
+	}
+
+	for _, move := range g.LegalMoves() {
+		if move.Drop && move.Moving.Type == pieceType && move.To == to {
+			return move, nil
+		}
+	}
+	return chess.Move{}, algebraicError{
+		algebraic: algebraic,
+		reason:    "cannot drop a " + pieceType.String() + " on " + to.String(),
+	}
+}
+
+// fairyPieceType returns the registered fairy piece whose
+// ShortName is char, which must be uppercase.
+func fairyPieceType(char byte) (chess.PieceType, bool) {
+	if char < 'A' || char > 'Z' {
+		return chess.PieceNone, false
+	}
+	t, ok := chess.ParsePieceType(char)
+	return t, ok && t > chess.PieceKing
 }
 
 // Algebraic returns the algebraic form for a given move. Does not detect
 // if the move puts the other person in check.
 func Algebraic(m chess.Move) string {
-	game := m.Snapshot
-	player := m.Moving.Color
+	// in Duck Chess, the duck's space follows the move
+	if m.HasDuck {
+		return pieceAlgebraic(m) + "," + m.Duck.String()
+	}
+	return pieceAlgebraic(m)
+}
+
+// pieceAlgebraic returns the algebraic form of the pieces moved by m.
+func pieceAlgebraic(m chess.Move) string {
+	if m.Drop {
+		return string(m.Moving.Type.ShortName()) + "@" + m.To.String()
+	}
+
+	game := m.Snapshot.Clone()
 	piece := m.Moving
 	from := m.Moving.Location
 	to := m.To
 
 	var builder strings.Builder
 
-	// detect castle, uses a `goto` to skip all the standard move notation...
-	// please don't get mad at me for using a goto...
-	if piece.Type == chess.PieceKing {
-		diff := to.File - from.File
-		if diff == -2 {
-			builder.WriteString("O-O-O")
-			return "O-O-O"
-		}
-		if diff == 2 {
-			builder.WriteString("O-O")
-			return "O-O-O"
+	// kings only ever move more than one file by castling, and always
+	// castle to the second file from the edge of the board
+	files, _ := game.Size()
+	diff := to.File - from.File
+	if m.Castle || piece.Type == chess.PieceKing && (diff >= 2 || diff <= -2) {
+		if to.File == files-2 {
+			return "O-O"
 		}
+		return "O-O-O"
 	}
 
+	_, capturing := game.PieceAt(to)
+	capturing = capturing || m.EnPassant
+
 	// piece to move
 	if piece.Type != chess.PiecePawn {
 		builder.WriteByte(byte(piece.Type.ShortName()))
-	}
 
-	// disambiguate the piece if needed
-	for _, each := range game.TypedAlivePieces(player, piece.Type) {
-		if each != piece {
-			var seesTarget bool
-			for _, space := range each.Seeing() {
-				if space == to {
-					seesTarget = true
-					break
-				}
-			}
-			if seesTarget {
-				if from.File != each.Location.File {
-					builder.WriteByte(byte(from.File + 'a'))
-				} else {
-					builder.WriteByte(byte(from.Rank + '1'))
-				}
-				break
+		// disambiguate the piece if needed
+		var ambiguous, sameFile, sameRank bool
+		for _, each := range game.LegalMoves() {
+			if each.Drop || each.Moving.Type != piece.Type || each.To != to || each.Moving.Location == from {
+				continue
 			}
+			ambiguous = true
+			sameFile = sameFile || each.Moving.Location.File == from.File
+			sameRank = sameRank || each.Moving.Location.Rank == from.Rank
+		}
+		if ambiguous && (!sameFile || sameRank) {
+			builder.WriteByte(byte(from.File + 'a'))
+		}
+		if ambiguous && sameFile {
+			builder.WriteString(strconv.Itoa(from.Rank + 1))
 		}
+	} else if capturing {
+		// pawn captures are always written with the file they came from
+		builder.WriteByte(byte(from.File + 'a'))
 	}
 
 	// if it is a capture
-	if game.BoardFileRank()[to.File][to.Rank].Type != chess.PieceNone {
-		builder.WriteByte('x')
-	} else if piece.Type == chess.PiecePawn && game.EnPassant == to {
+	if capturing {
 		builder.WriteByte('x')
 	}
 
@@ -221,7 +330,7 @@ func Algebraic(m chess.Move) string {
 	builder.WriteString(to.String())
 
 	// en passant
-	if piece.Type == chess.PiecePawn && game.EnPassant == to {
+	if m.EnPassant {
 		builder.WriteString("e.p.")
 	}
 
@@ -245,10 +354,9 @@ func PGNAlgebraic(m chess.Move) (string, error) {
 	nextState := m.Snapshot.Clone()
 	nextState.MakeMoveUnconditionally(m)
 
-	if nextState.InCheck(player.Other()) {
-		alg += "+"
-	}
 	if nextState.InCheckmate(player.Other()) {
+		alg += "#"
+	} else if nextState.InCheck(player.Other()) {
 		alg += "+"
 	}
 
@@ -0,0 +1,88 @@
+package encoder
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/deanveloper/chess"
+)
+
+// the tags which are always written first in BPGN, in order
+var bpgnTagOrder = []string{"Event", "Site", "Date", "Round", "WhiteA", "BlackA", "WhiteB", "BlackB", "Result"}
+
+// BPGNReader returns a reader for a Bughouse match that reads the data
+// into BPGN notation, where each move is numbered with the board that it
+// was made on, such as "1A. e4 1b. d5". The Result and Termination tags
+// are filled in from the completion state unless they are already in tags.
+func BPGNReader(tags map[string]string, moves <-chan chess.BughouseMove, completion <-chan chess.CompletionState) io.Reader {
+	complete := <-completion
+
+	clonedTags := make(map[string]string)
+	for k, v := range tags {
+		clonedTags[k] = v
+	}
+	if _, ok := clonedTags["Result"]; !ok {
+		clonedTags["Result"] = complete.Result()
+	}
+	if _, ok := clonedTags["Termination"]; !ok {
+		clonedTags["Termination"] = terminationTag(complete)
+	}
+
+	return io.MultiReader(tagsReader(clonedTags, bpgnTagOrder), &bughouseMoveTextReader{moves: moves}, completionStateReader(complete))
+}
+
+type bughouseMoveTextReader struct {
+	moves <-chan chess.BughouseMove
+
+	movesRead int
+	curMove   string
+	strIndex  int
+
+	err error
+}
+
+func (r *bughouseMoveTextReader) Read(b []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	var bytesRead int
+
+	for bytesRead < len(b) {
+		// finish reading the current move before reading the next
+		if r.strIndex < len(r.curMove) {
+			copied := copy(b[bytesRead:], r.curMove[r.strIndex:])
+			r.strIndex += copied
+			bytesRead += copied
+			continue
+		}
+
+		move, ok := <-r.moves
+		if !ok {
+			r.err = io.EOF
+			return bytesRead, io.EOF
+		}
+
+		alg, err := PGNAlgebraic(move.Move)
+		if err != nil {
+			r.err = err
+			return bytesRead, err
+		}
+
+		// the board is uppercase for white's moves, and lowercase for black's
+		board := byte('A' + move.Board)
+		if move.Move.Moving.Color == chess.Black {
+			board = board - 'A' + 'a'
+		}
+		alg = fmt.Sprintf("%d%c. %s", move.Move.Snapshot.Fullmove/2+1, board, alg)
+		if r.movesRead > 0 {
+			alg = " " + alg
+		}
+
+		r.curMove = alg
+		r.strIndex = 0
+		r.movesRead++
+	}
+
+	return bytesRead, nil
+}
@@ -1,6 +1,7 @@
 package encoder
 
 import (
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -8,26 +9,46 @@ import (
 	"github.com/deanveloper/chess"
 )
 
-// FENReader returns a reader for a game that reads
-// the data in Forsyth-Edwards Notation.
+// FENReader returns a reader for a game that reads the data in
+// Forsyth-Edwards Notation. Chess960 castling rights are written
+// as X-FEN, which is the same as FEN for classic start positions.
 func FENReader(game *chess.Game) io.Reader {
+	return fenReader(game, false)
+}
+
+// ShredderFENReader returns a reader for a game that reads the data in
+// Shredder-FEN, which writes castling rights as the files of the
+// castling rooks rather than KQkq.
+func ShredderFENReader(game *chess.Game) io.Reader {
+	return fenReader(game, true)
+}
+
+func fenReader(game *chess.Game, shredder bool) io.Reader {
 
-	board := game.BoardRankFile()
+	board := game.Board()
+	files, ranks := game.Size()
 
+	fields := make([]string, 0, 6)
 	var builder strings.Builder
 
 	// 1st field: board state
-	for rank := 7; rank >= 0; rank-- {
-		var emptySpots byte
-		for file := 0; file < 8; file++ {
-			p := board[rank][file]
-			if p.Type == chess.PieceNone {
+	for rank := ranks - 1; rank >= 0; rank-- {
+		var emptySpots int
+		for file := 0; file < files; file++ {
+			p := board[file][rank]
+			duck := game.Duck.Has(chess.Space{File: file, Rank: rank})
+			if p.Type == chess.PieceNone && !duck {
 				emptySpots++
 			} else {
 				if emptySpots > 0 {
-					builder.WriteByte(emptySpots + '0')
+					builder.WriteString(strconv.Itoa(emptySpots))
 					emptySpots = 0
 				}
+				if duck {
+					// the duck in Duck Chess
+					builder.WriteByte('*')
+					continue
+				}
 				name := p.Type.ShortName()
 				if p.Color == chess.Black {
 					name = name - 'A' + 'a' // lowercase
@@ -36,64 +57,334 @@ func FENReader(game *chess.Game) io.Reader {
 			}
 		}
 		if emptySpots > 0 {
-			builder.WriteByte(emptySpots + '0')
+			builder.WriteString(strconv.Itoa(emptySpots))
+		}
+		if rank > 0 {
+			builder.WriteByte('/')
 		}
-		builder.WriteByte('/')
 	}
-
-	builder.WriteByte(' ')
+	fields = append(fields, builder.String())
 
 	// second field: player to move
 	if game.Turn() == chess.White {
-		builder.WriteByte('w')
+		fields = append(fields, "w")
 	} else {
-		builder.WriteByte('b')
+		fields = append(fields, "b")
 	}
 
-	builder.WriteByte(' ')
-
 	// third field: castling availability
-	var any bool
-	if game.Castles.WhiteKing {
-		builder.WriteByte('K')
-		any = true
-	}
-	if game.Castles.WhiteQueen {
-		builder.WriteByte('Q')
-		any = true
-	}
-	if game.Castles.BlackKing {
-		builder.WriteByte('k')
-		any = true
-	}
-	if game.Castles.BlackQueen {
-		builder.WriteByte('q')
-		any = true
+	builder.Reset()
+	for _, color := range [...]chess.Color{chess.White, chess.Black} {
+		for _, kingSide := range [...]bool{true, false} {
+			if game.Castles.Can(color, kingSide) {
+				builder.WriteByte(castleChar(game, board, color, kingSide, shredder))
+			}
+		}
 	}
-	if !any {
+	if builder.Len() == 0 {
 		builder.WriteByte('-')
 	}
-	builder.WriteByte(' ')
+	fields = append(fields, builder.String())
 
 	// fourth field: en passant square
 	if game.EnPassant.Rank != 0 {
-		builder.WriteString(game.EnPassant.String())
+		fields = append(fields, game.EnPassant.String())
 	} else {
-		builder.WriteByte('-')
+		fields = append(fields, "-")
 	}
 
-	builder.WriteByte(' ')
+	// fifth field: halfmove clock
+	fields = append(fields, strconv.Itoa(game.Halfmove))
+
+	// sixth field: fullmove number (game.Fullmove counts plies)
+	fields = append(fields, strconv.Itoa(game.Fullmove/2+1))
+
+	// the variant may add its own fields
+	fields = game.Variant().WriteFEN(game, fields)
+
+	return strings.NewReader(strings.Join(fields, " "))
+}
+
+type fenError struct {
+	fen    string
+	reason string
+}
+
+func (f fenError) Error() string {
+	return fmt.Sprintf("parsing FEN %q: %s", f.fen, f.reason)
+}
+
+// FromFEN returns a new game from a position in Forsyth-Edwards Notation.
+func FromFEN(fen string) (*chess.Game, error) {
+	return FromVariantFEN(chess.Standard{}, fen)
+}
+
+// FromVariantFEN returns a new game played by variant from a position in
+// Forsyth-Edwards Notation, including any of the variant's extensions to it.
+func FromVariantFEN(variant chess.Variant, fen string) (*chess.Game, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 6 {
+		return nil, fenError{fen: fen, reason: "expected 6 fields"}
+	}
+
+	// 1st field: board state, which may be followed by a bracketed
+	// extension and have "~" markers and a "*" duck that the variant reads
+	placement := fields[0]
+	if i := strings.IndexByte(placement, '['); i >= 0 {
+		placement = placement[:i]
+	}
+
+	// the board may be larger than 8x8, in which case there are
+	// more ranks, and more files which may need two digits to skip
+	ranks := strings.Split(placement, "/")
+	if len(ranks) > 16 {
+		return nil, fenError{fen: fen, reason: "too many ranks"}
+	}
+	files := -1
+	board := make([][]chess.Piece, 16)
+	for file := range board {
+		board[file] = make([]chess.Piece, len(ranks))
+	}
+	for i, rankStr := range ranks {
+		rank := len(ranks) - 1 - i
+		file := 0
+		for j := 0; j < len(rankStr); j++ {
+			char := rankStr[j]
+			if char >= '1' && char <= '9' {
+				empty := int(char - '0')
+				if j+1 < len(rankStr) && rankStr[j+1] >= '0' && rankStr[j+1] <= '9' {
+					empty = empty*10 + int(rankStr[j+1]-'0')
+					j++
+				}
+				file += empty
+				continue
+			}
+			if char == '~' {
+				continue
+			}
+			if char == '*' {
+				// the duck, which the variant reads
+				file++
+				continue
+			}
+
+			pieceType, ok := fenPieceType(char)
+			if !ok {
+				return nil, fenError{fen: fen, reason: fmt.Sprintf("unknown piece %q", char)}
+			}
+			if file >= len(board) {
+				return nil, fenError{fen: fen, reason: "rank " + strconv.Itoa(rank+1) + " has too many files"}
+			}
+			color := chess.White
+			if char >= 'a' && char <= 'z' {
+				color = chess.Black
+			}
+			board[file][rank] = chess.Piece{
+				Type:     pieceType,
+				Color:    color,
+				Location: chess.Space{File: file, Rank: rank},
+			}
+			file++
+		}
+		if file == 0 {
+			return nil, fenError{fen: fen, reason: "rank " + strconv.Itoa(rank+1) + " is empty"}
+		}
+		if file > len(board) {
+			return nil, fenError{fen: fen, reason: "rank " + strconv.Itoa(rank+1) + " has too many files"}
+		}
+		if files == -1 {
+			files = file
+		}
+		if file != files {
+			return nil, fenError{fen: fen, reason: "rank " + strconv.Itoa(rank+1) + " does not have " + strconv.Itoa(files) + " files"}
+		}
+	}
+	board = board[:files]
+
+	game := &chess.Game{}
+	game.SetVariant(variant)
+	game.InitCustomBoard(board)
+
+	// second field: player to move
+	var blackToMove bool
+	switch fields[1] {
+	case "w":
+	case "b":
+		blackToMove = true
+	default:
+		return nil, fenError{fen: fen, reason: "invalid player to move " + fields[1]}
+	}
+
+	// third field: castling availability, which may be
+	// KQkq, X-FEN or Shredder-FEN
+	if fields[2] != "-" {
+		for _, char := range []byte(fields[2]) {
+			if !parseCastle(game, board, char) {
+				return nil, fenError{fen: fen, reason: "invalid castling availability " + fields[2]}
+			}
+		}
+	}
+
+	// fourth field: en passant square
+	if fields[3] != "-" {
+		target, ok := parseSpace(fields[3])
+		if !ok || !game.OnBoard(target) {
+			return nil, fenError{fen: fen, reason: "invalid en passant square " + fields[3]}
+		}
+		game.EnPassant = target
+	}
 
 	// fifth field: halfmove clock
-	builder.WriteString(strconv.Itoa(game.Halfmove))
-	builder.WriteByte(' ')
+	halfmove, err := strconv.Atoi(fields[4])
+	if err != nil || halfmove < 0 {
+		return nil, fenError{fen: fen, reason: "invalid halfmove clock " + fields[4]}
+	}
+	game.Halfmove = halfmove
 
 	// sixth field: fullmove number
-	fullMove := game.Fullmove
-	if fullMove == 0 {
-		fullMove = 1
+	fullmove, err := strconv.Atoi(fields[5])
+	if err != nil || fullmove < 1 {
+		return nil, fenError{fen: fen, reason: "invalid fullmove number " + fields[5]}
+	}
+	game.Fullmove = (fullmove - 1) * 2
+	if blackToMove {
+		game.Fullmove++
+	}
+
+	// the rest of the FEN is up to the variant
+	if err := variant.ReadFEN(game, fields); err != nil {
+		return nil, fenError{fen: fen, reason: err.Error()}
+	}
+
+	return game, nil
+}
+
+// castleChar returns the character for color's right to castle on the
+// given side. In Shredder-FEN, it is always the file of the castling rook.
+// Otherwise it is K or Q, unless another rook is further out on the same
+// side than the castling rook, in which case X-FEN uses the rook's file.
+func castleChar(game *chess.Game, board [][]chess.Piece, color chess.Color, kingSide, shredder bool) byte {
+	files := backRank(board, color)
+	castleFiles := game.Castles.Files(color)
+
+	rookFile, char := castleFiles.QueenRook, byte('Q')
+	if kingSide {
+		rookFile, char = castleFiles.KingRook, 'K'
+	}
+	if shredder || outermostRook(files, color, castleFiles.King, kingSide) != rookFile {
+		char = byte(rookFile) + 'A'
+	}
+
+	if color == chess.Black {
+		char = char - 'A' + 'a' // lowercase
+	}
+	return char
+}
+
+// parseCastle gives the player a castling right from a character in the
+// castling availability field of a FEN. It returns false if the character
+// is invalid, or if there is not a king and rook to castle with.
+func parseCastle(game *chess.Game, board [][]chess.Piece, char byte) bool {
+	color := chess.White
+	if char >= 'a' && char <= 'z' {
+		color = chess.Black
+		char = char - 'a' + 'A'
+	}
+	files := backRank(board, color)
+
+	king := -1
+	for file, p := range files {
+		if p.Type == chess.PieceKing && p.Color == color {
+			king = file
+		}
+	}
+	if king == -1 {
+		return false
 	}
-	builder.WriteString(strconv.Itoa(fullMove))
 
-	return strings.NewReader(builder.String())
+	var rook int
+	switch {
+	case char == 'K':
+		rook = outermostRook(files, color, king, true)
+	case char == 'Q':
+		rook = outermostRook(files, color, king, false)
+	case char >= 'A' && int(char-'A') < len(files):
+		rook = int(char - 'A')
+		if files[rook].Type != chess.PieceRook || files[rook].Color != color {
+			return false
+		}
+	default:
+		return false
+	}
+	if rook == -1 || rook == king {
+		return false
+	}
+
+	kingSide := rook > king
+	castleFiles := game.Castles.Files(color)
+	castleFiles.King = king
+	if kingSide {
+		castleFiles.KingRook = rook
+	} else {
+		castleFiles.QueenRook = rook
+	}
+	game.Castles.SetFiles(color, castleFiles)
+	game.Castles.Set(color, kingSide, true)
+	return true
+}
+
+// outermostRook returns the file of color's rook furthest from the king on
+// the given side of the back rank, or -1 if there are no rooks there.
+func outermostRook(files []chess.Piece, color chess.Color, king int, kingSide bool) int {
+	for i := range files {
+		file := i
+		if kingSide {
+			file = len(files) - 1 - i
+		}
+		if file == king {
+			break
+		}
+		if files[file].Type == chess.PieceRook && files[file].Color == color {
+			return file
+		}
+	}
+	return -1
+}
+
+// backRank returns the pieces on color's back rank of a board
+// in [file][rank] form, from the a-file onwards.
+func backRank(board [][]chess.Piece, color chess.Color) []chess.Piece {
+	files := make([]chess.Piece, len(board))
+	for file := range files {
+		rank := 0
+		if color == chess.Black {
+			rank = len(board[file]) - 1
+		}
+		files[file] = board[file][rank]
+	}
+	return files
+}
+
+// parseSpace returns the space written as str, such as "e4", or
+// "j10" on a board larger than 8x8. It does not check that the
+// space is on the board of a particular game.
+func parseSpace(str string) (chess.Space, bool) {
+	if len(str) < 2 || len(str) > 3 || str[0] < 'a' || str[0] > 'z' {
+		return chess.Space{}, false
+	}
+	rank := 0
+	for _, char := range []byte(str[1:]) {
+		if char < '0' || char > '9' {
+			return chess.Space{}, false
+		}
+		rank = rank*10 + int(char-'0')
+	}
+	s := chess.Space{File: int(str[0] - 'a'), Rank: rank - 1}
+	return s, s.Valid()
+}
+
+// fenPieceType returns the piece type for a FEN piece character,
+// regardless of its case, including registered fairy pieces.
+func fenPieceType(char byte) (chess.PieceType, bool) {
+	return chess.ParsePieceType(char)
 }
@@ -3,25 +3,59 @@ package encoder
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/deanveloper/chess"
 )
 
 // PGNReader returns a reader for a game that
-// reads the data into PGN notation.
+// reads the data into PGN notation. The Result and Termination
+// tags are filled in from the completion state unless they are
+// already in tags.
 func PGNReader(tags map[string]string, moves <-chan chess.Move, completion <-chan chess.CompletionState) io.Reader {
-	return io.MultiReader(tagsReader(tags), &moveTextReader{moves: moves}, completionStateReader(completion))
+	complete := <-completion
+
+	clonedTags := make(map[string]string)
+	for k, v := range tags {
+		clonedTags[k] = v
+	}
+	if _, ok := clonedTags["Result"]; !ok {
+		clonedTags["Result"] = complete.Result()
+	}
+	if _, ok := clonedTags["Termination"]; !ok {
+		clonedTags["Termination"] = terminationTag(complete)
+	}
+
+	return io.MultiReader(tagsReader(clonedTags, pgnTagOrder), &moveTextReader{moves: moves}, completionStateReader(complete))
+}
+
+// terminationTag returns the value of the Termination tag, which
+// is limited to a few values by the PGN standard.
+func terminationTag(complete chess.CompletionState) string {
+	switch {
+	case !complete.Done:
+		return "unterminated"
+	case complete.Reason == chess.TerminationTimeout,
+		complete.Reason == chess.TerminationTimeoutVsInsufficientMaterial:
+		return "time forfeit"
+	default:
+		return "normal"
+	}
 }
 
-func tagsReader(tags map[string]string) io.Reader {
+// the tags which are always written first, in order
+var pgnTagOrder = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// tagsReader reads the tags in order, which are written
+// even if they are omitted, followed by the rest.
+func tagsReader(tags map[string]string, order []string) io.Reader {
 	var builder strings.Builder
 
 	clonedTags := make(map[string]string)
 	for k, v := range tags {
 		clonedTags[k] = v
 	}
-	order := []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
 	for _, key := range order {
 		if val, ok := clonedTags[key]; ok {
 			builder.WriteString(fullTag(key, val))
@@ -30,8 +64,13 @@ func tagsReader(tags map[string]string) io.Reader {
 			builder.WriteString(fullTag(key, "omitted"))
 		}
 	}
-	for key, val := range clonedTags {
-		builder.WriteString(fullTag(key, val))
+	var rest []string
+	for key := range clonedTags {
+		rest = append(rest, key)
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		builder.WriteString(fullTag(key, clonedTags[key]))
 	}
 
 	builder.WriteByte('\n')
@@ -60,15 +99,15 @@ func (r *moveTextReader) Read(b []byte) (int, error) {
 
 	var bytesRead int
 
-	// step 1: finish reading last move
-	if r.strIndex < len(r.curMove) {
-		copied := copy(b[bytesRead:], r.curMove[r.strIndex:])
-		r.strIndex += copied
-		bytesRead += copied
-	}
-
 	for bytesRead < len(b) {
-		var move chess.Move
+		// finish reading the current move before reading the next
+		if r.strIndex < len(r.curMove) {
+			copied := copy(b[bytesRead:], r.curMove[r.strIndex:])
+			r.strIndex += copied
+			bytesRead += copied
+			continue
+		}
+
 		move, ok := <-r.moves
 		if !ok {
 			r.err = io.EOF
@@ -83,36 +122,24 @@ func (r *moveTextReader) Read(b []byte) (int, error) {
 		if r.movesRead%2 == 0 {
 			alg = fmt.Sprintf("%d. %s", r.movesRead/2+1, alg)
 		}
-		if r.movesRead < len(r.moves)-1 {
-			alg += " "
+		if r.movesRead > 0 {
+			alg = " " + alg
 		}
 
-		copied := copy(b[bytesRead:], alg[r.strIndex:])
-		r.strIndex += copied
-		bytesRead += copied
-
-		if r.strIndex == len(alg) {
-			r.movesRead++
-			r.strIndex = 0
-		}
+		r.curMove = alg
+		r.strIndex = 0
+		r.movesRead++
 	}
 
 	return bytesRead, nil
 }
 
-func completionStateReader(completion <-chan chess.CompletionState) io.Reader {
-	var final string
-
-	complete := <-completion
+// completionStateReader reads the game termination marker, preceded
+// by a comment describing how the game ended if it is done.
+func completionStateReader(complete chess.CompletionState) io.Reader {
+	final := " " + complete.Result()
 	if complete.Done {
-		switch {
-		case complete.Draw:
-			final = " 1/2-1/2"
-		case complete.Winner == chess.White:
-			final = " 1-0"
-		case complete.Winner == chess.Black:
-			final = " 0-1"
-		}
+		final = " {" + complete.String() + "}" + final
 	}
 
 	return strings.NewReader(final)
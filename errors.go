@@ -8,6 +8,24 @@ import (
 var (
 	// ErrParseMove represents the error that occurs when the game is unable to parse a move.
 	ErrParseMove = errors.New("unable to parse move")
+
+	// ErrNoUndo represents the error that occurs when there are not enough moves to take back.
+	ErrNoUndo = errors.New("no moves to take back")
+
+	// ErrNoRedo represents the error that occurs when there are not enough undone moves to redo.
+	ErrNoRedo = errors.New("no moves to redo")
+
+	// ErrGameOver represents the error that occurs when trying to change the outcome of a finished game.
+	ErrGameOver = errors.New("the game is over")
+
+	// ErrNoDrawOffer represents the error that occurs when responding to a draw offer that was not made.
+	ErrNoDrawOffer = errors.New("no draw has been offered")
+
+	// ErrOwnDrawOffer represents the error that occurs when a player responds to their own draw offer.
+	ErrOwnDrawOffer = errors.New("cannot respond to your own draw offer")
+
+	// ErrCannotClaimDraw represents the error that occurs when claiming a draw that the rules do not allow.
+	ErrCannotClaimDraw = errors.New("a draw cannot be claimed")
 )
 
 // MoveError represents an error caused by an invalid move.
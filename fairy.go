@@ -0,0 +1,129 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the number of piece types that can exist, including
+// PieceNone and the fairy pieces that may be registered
+const pieceTypes = 16
+
+// PieceDef defines a fairy piece, which can be registered with RegisterPiece.
+type PieceDef struct {
+	// Name is the name of the piece, such as "Archbishop".
+	Name string
+
+	// ShortName is the uppercase letter of the piece, which is
+	// used by FEN and algebraic notation, such as 'A'.
+	ShortName byte
+
+	// Symbol is the rune that the piece is drawn with. The
+	// ShortName is used if it is zero.
+	Symbol rune
+
+	// Betza describes how the piece moves in Betza notation, such as "BN"
+	// for a piece which moves as a bishop or a knight. See
+	// https://www.chessvariants.com/piececlopedia.dir/betza.html for
+	// more information about Betza notation.
+	Betza string
+}
+
+// Some common fairy pieces, which may be registered with RegisterPiece.
+var (
+	Archbishop  = PieceDef{Name: "Archbishop", ShortName: 'A', Betza: "BN"}
+	Chancellor  = PieceDef{Name: "Chancellor", ShortName: 'C', Betza: "RN"}
+	Amazon      = PieceDef{Name: "Amazon", ShortName: 'M', Betza: "QN"}
+	Camel       = PieceDef{Name: "Camel", ShortName: 'L', Betza: "C"}
+	Grasshopper = PieceDef{Name: "Grasshopper", ShortName: 'G', Betza: "gQ"}
+)
+
+// a fairy piece that has been registered
+type fairyPiece struct {
+	def   PieceDef
+	moves []betzaMove
+}
+
+// the registered fairy pieces, indexed by PieceType - firstFairyPiece
+var fairyPieces []fairyPiece
+
+// the first PieceType of the fairy pieces
+const firstFairyPiece = PieceKing + 1
+
+// RegisterPiece registers a fairy piece, and returns its PieceType. Once it
+// is registered, it can be placed on the board of any game, such as with
+// Game.InitCustom or in FEN, and is moved as described by its Betza.
+// Pieces should be registered before any game is played, such as in an
+// init function.
+func RegisterPiece(def PieceDef) (PieceType, error) {
+	if def.Name == "" {
+		return PieceNone, errors.New("piece must have a name")
+	}
+	if def.ShortName < 'A' || def.ShortName > 'Z' {
+		return PieceNone, fmt.Errorf("short name of %s must be an uppercase letter", def.Name)
+	}
+	if t, ok := ParsePieceType(def.ShortName); ok {
+		return PieceNone, fmt.Errorf("short name of %s is already used by %s", def.Name, t)
+	}
+	if int(firstFairyPiece)+len(fairyPieces) >= pieceTypes {
+		return PieceNone, fmt.Errorf("cannot register %s, as only %d fairy pieces may be registered", def.Name, pieceTypes-int(firstFairyPiece))
+	}
+
+	moves, err := parseBetza(def.Betza)
+	if err != nil {
+		return PieceNone, fmt.Errorf("parsing betza %q of %s: %v", def.Betza, def.Name, err)
+	}
+
+	fairyPieces = append(fairyPieces, fairyPiece{def: def, moves: moves})
+	return firstFairyPiece + PieceType(len(fairyPieces)-1), nil
+}
+
+// MustRegisterPiece is like RegisterPiece, but panics if the piece cannot be registered.
+func MustRegisterPiece(def PieceDef) PieceType {
+	t, err := RegisterPiece(def)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// ParsePieceType returns the type of piece whose ShortName is
+// shortName in either case, including registered fairy pieces.
+func ParsePieceType(shortName byte) (PieceType, bool) {
+	if shortName >= 'a' && shortName <= 'z' {
+		shortName = shortName - 'a' + 'A'
+	}
+	for t := PiecePawn; t < firstFairyPiece+PieceType(len(fairyPieces)); t++ {
+		if t.ShortName() == shortName {
+			return t, true
+		}
+	}
+	return PieceNone, false
+}
+
+// FairyPieces returns the types of each registered fairy piece.
+func FairyPieces() []PieceType {
+	types := make([]PieceType, len(fairyPieces))
+	for i := range types {
+		types[i] = firstFairyPiece + PieceType(i)
+	}
+	return types
+}
+
+// fairy returns the registered fairy piece of type t.
+func (t PieceType) fairy() *fairyPiece {
+	return &fairyPieces[t-firstFairyPiece]
+}
+
+// fairyAttacked returns if any of by's fairy pieces attack sq.
+func (g *Game) fairyAttacked(sq int, by Color) bool {
+	them := g.colors[colorIndex(by)]
+	for t := firstFairyPiece; t < firstFairyPiece+PieceType(len(fairyPieces)); t++ {
+		for pieces := g.types[t] & them; pieces != 0; {
+			if g.attacks(t, by, pieces.pop())&squareBit(sq) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/deanveloper/chess"
+	"github.com/deanveloper/chess/encoder"
+)
+
+var (
+	camel       = chess.MustRegisterPiece(chess.Camel)
+	grasshopper = chess.MustRegisterPiece(chess.Grasshopper)
+)
+
+func TestRegisterPiece(t *testing.T) {
+	if _, err := chess.RegisterPiece(chess.PieceDef{Name: "Nightrider", ShortName: 'N', Betza: "NN"}); err == nil {
+		t.Error("registered a piece with the knight's short name")
+	}
+	if _, err := chess.RegisterPiece(chess.PieceDef{Name: "Unknown", ShortName: 'U', Betza: "fX"}); err == nil {
+		t.Error("registered a piece with an unknown atom")
+	}
+
+	// Capablanca and Grand Chess share the archbishop and chancellor
+	game := &chess.Game{}
+	game.InitVariant(chess.Capablanca{})
+	game.InitVariant(chess.GrandChess{})
+	for _, def := range []chess.PieceDef{chess.Archbishop, chess.Chancellor} {
+		if piece, ok := chess.ParsePieceType(def.ShortName); !ok || piece.String() != def.Name {
+			t.Errorf("%c is %v, want the %s", def.ShortName, piece, def.Name)
+		}
+		var registered int
+		for _, piece := range chess.FairyPieces() {
+			if piece.String() == def.Name {
+				registered++
+			}
+		}
+		if registered != 1 {
+			t.Errorf("%s is registered %d times, want once", def.Name, registered)
+		}
+	}
+}
+
+func TestFairyPieces(t *testing.T) {
+	fen := "4k3/4p3/8/8/8/8/8/L3GK2 b - - 0 1"
+	game, err := encoder.FromFEN(fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, encoder.FENReader(game)); got != fen {
+		t.Errorf("FEN = %q, want %q", got, fen)
+	}
+
+	// the grasshopper hops over the pawn and lands on the king
+	if p, _ := game.PieceAt(chess.Space{File: 4, Rank: 0}); p.Type != grasshopper {
+		t.Errorf("piece on e1 = %v, want a Grasshopper", p)
+	}
+	if !game.InCheck(chess.Black) {
+		t.Error("black is not in check by the grasshopper")
+	}
+
+	camels := game.TypedAlivePieces(chess.White, camel)
+	if len(camels) != 1 || camels[0].Type.String() != "Camel" {
+		t.Fatalf("camels = %v", camels)
+	}
+	want := map[chess.Space]bool{{File: 3, Rank: 1}: true, {File: 1, Rank: 3}: true}
+	for _, space := range camels[0].Seeing() {
+		if !want[space] {
+			t.Errorf("camel sees %v, want only d2 and b4", space)
+		}
+		delete(want, space)
+	}
+	if len(want) != 0 {
+		t.Errorf("camel does not see %v", want)
+	}
+
+	move, err := encoder.FromAlgebraic(game, "Kd8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	move, err = encoder.FromAlgebraic(game, "Lb4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	if got := encoder.Algebraic(move); got != "Lb4" {
+		t.Errorf("algebraic = %q, want %q", got, "Lb4")
+	}
+}
@@ -0,0 +1,443 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+)
+
+// the number of files and ranks on the board of four-player chess
+const fourPlayerSize = 14
+
+// Player is one of the four players of a FourPlayerGame.
+type Player byte
+
+// The enum of players, in the order that they move,
+// which is clockwise around the board.
+const (
+	PlayerRed Player = iota
+	PlayerBlue
+	PlayerYellow
+	PlayerGreen
+)
+
+func (p Player) String() string {
+	return [...]string{"Red", "Blue", "Yellow", "Green"}[p]
+}
+
+// next returns the player who moves after p.
+func (p Player) next() Player {
+	return (p + 1) % 4
+}
+
+// the points for capturing each type of piece in four-player chess,
+// indexed by PieceType. fairy pieces are worth as much as a queen.
+var fourPlayerPoints = [...]int{
+	PiecePawn:   1,
+	PieceKnight: 3,
+	PieceBishop: 5,
+	PieceRook:   5,
+	PieceQueen:  9,
+	PieceKing:   20,
+}
+
+// the points for checkmating a player, or for being stalemated
+const fourPlayerMatePoints = 20
+
+// FourPlayerPiece is a piece in a FourPlayerGame.
+type FourPlayerPiece struct {
+	Type     PieceType
+	Player   Player
+	Location Space
+}
+
+func (p FourPlayerPiece) String() string {
+	return fmt.Sprintf("%v %v on %v", p.Player, p.Type, p.Location)
+}
+
+// FourPlayerMove is a move in a FourPlayerGame.
+type FourPlayerMove struct {
+	From, To  Space
+	Promotion PieceType
+}
+
+// FourPlayerCompletion is the completion state of a FourPlayerGame.
+type FourPlayerCompletion struct {
+	Done bool
+
+	// Draw is true if more than one player finished with the highest score.
+	Draw bool
+
+	// Winner is the player with the highest score.
+	Winner Player
+}
+
+// a square of the board of four-player chess
+type fourPlayerSquare struct {
+	t      PieceType
+	player Player
+}
+
+// FourPlayerGame is a game of four-player chess, played on a 14x14 board
+// without its 3x3 corners. Red starts at the bottom of the board, and each
+// player sits clockwise from the last, with their pieces arranged the same
+// way from their side of the board.
+//
+// The pieces move as in standard chess, without castling or en passant,
+// and pawns promote on their eighth rank, which is the middle of the
+// board. A player whose king is checkmated or captured is eliminated, as
+// is a player who is stalemated, and an eliminated player's pieces stay
+// on the board, but no longer move or give check. Players score points
+// for capturing the pieces of players who have not been eliminated, and
+// for checkmating or being stalemated. Once only one player is left, the
+// player with the most points wins.
+type FourPlayerGame struct {
+	board [fourPlayerSize * fourPlayerSize]fourPlayerSquare
+
+	// the player who moves next
+	turn Player
+
+	// Scores holds the points of each player, indexed by Player.
+	Scores [4]int
+
+	// Eliminated holds if each player has been eliminated, indexed by Player.
+	Eliminated [4]bool
+
+	// the moves made so far
+	History []FourPlayerMove
+
+	Completion FourPlayerCompletion
+}
+
+// Init sets up the start position of four-player chess.
+func (g *FourPlayerGame) Init() {
+	back := [...]PieceType{PieceRook, PieceKnight, PieceBishop, PieceQueen, PieceKing, PieceBishop, PieceKnight, PieceRook}
+
+	var pieces []FourPlayerPiece
+	for p := PlayerRed; p <= PlayerGreen; p++ {
+		for i, t := range back {
+			first := Space{File: i + 3, Rank: 0}
+			second := Space{File: i + 3, Rank: 1}
+			pieces = append(pieces,
+				FourPlayerPiece{Type: t, Player: p, Location: fourPlayerAbsolute(p, first)},
+				FourPlayerPiece{Type: PiecePawn, Player: p, Location: fourPlayerAbsolute(p, second)},
+			)
+		}
+	}
+	g.InitCustom(pieces)
+}
+
+// InitCustom initializes g to a custom layout, with red to move.
+// It panics if any piece is not on the board.
+func (g *FourPlayerGame) InitCustom(pieces []FourPlayerPiece) {
+	*g = FourPlayerGame{}
+	for _, p := range pieces {
+		if !g.OnBoard(p.Location) {
+			panic(fmt.Sprintf("%v is not on the board", p))
+		}
+		g.board[g.square(p.Location)] = fourPlayerSquare{t: p.Type, player: p.Player}
+	}
+}
+
+// fourPlayerAbsolute returns the space that is at s from p's side of
+// the board, where red's side is the bottom, so that each player's
+// first rank is the edge of the board closest to them.
+func fourPlayerAbsolute(p Player, s Space) Space {
+	for i := Player(0); i < p; i++ {
+		s = Space{File: s.Rank, Rank: fourPlayerSize - 1 - s.File}
+	}
+	return s
+}
+
+// fourPlayerRelative returns the space s as seen from p's
+// side of the board, which undoes fourPlayerAbsolute.
+func fourPlayerRelative(p Player, s Space) Space {
+	for i := Player(0); i < p; i++ {
+		s = Space{File: fourPlayerSize - 1 - s.Rank, Rank: s.File}
+	}
+	return s
+}
+
+// OnBoard returns if s is on the board, which does not have its corners.
+func (g *FourPlayerGame) OnBoard(s Space) bool {
+	corner := func(n int) bool {
+		return n < 3 || n >= fourPlayerSize-3
+	}
+	return s.within(fourPlayerSize, fourPlayerSize) && !(corner(s.File) && corner(s.Rank))
+}
+
+func (g *FourPlayerGame) square(s Space) int {
+	return s.Rank*fourPlayerSize + s.File
+}
+
+func (g *FourPlayerGame) occupied(s Space) bool {
+	return g.board[g.square(s)].t != PieceNone
+}
+
+// Turn returns the player who moves next.
+func (g *FourPlayerGame) Turn() Player {
+	return g.turn
+}
+
+// PieceAt returns the piece at a given space, and an `ok`
+// boolean on if there was a piece on that space at all.
+func (g *FourPlayerGame) PieceAt(s Space) (FourPlayerPiece, bool) {
+	if !g.OnBoard(s) || !g.occupied(s) {
+		return FourPlayerPiece{}, false
+	}
+	sq := g.board[g.square(s)]
+	return FourPlayerPiece{Type: sq.t, Player: sq.player, Location: s}, true
+}
+
+// Pieces returns the pieces of p that are on the board.
+func (g *FourPlayerGame) Pieces(p Player) []FourPlayerPiece {
+	var pieces []FourPlayerPiece
+	for rank := 0; rank < fourPlayerSize; rank++ {
+		for file := 0; file < fourPlayerSize; file++ {
+			if piece, ok := g.PieceAt(Space{File: file, Rank: rank}); ok && piece.Player == p {
+				pieces = append(pieces, piece)
+			}
+		}
+	}
+	return pieces
+}
+
+// Seeing returns all of the spaces that the piece on s can see, the same
+// way as Piece.Seeing. Pieces of eliminated players do not see anything.
+func (g *FourPlayerGame) Seeing(s Space) []Space {
+	var seeing []Space
+	for _, mv := range g.pseudoMoves(s) {
+		if mv.Promotion == PieceNone || mv.Promotion == PieceQueen {
+			seeing = append(seeing, mv.To)
+		}
+	}
+	return seeing
+}
+
+// pseudoMoves returns the moves of the piece on from, without
+// considering if they leave its player's king attacked.
+func (g *FourPlayerGame) pseudoMoves(from Space) []FourPlayerMove {
+	piece, ok := g.PieceAt(from)
+	if !ok || g.Eliminated[piece.Player] {
+		return nil
+	}
+
+	var moves []FourPlayerMove
+	add := func(to Space) {
+		if piece.Type == PiecePawn && fourPlayerRelative(piece.Player, to).Rank == 7 {
+			for _, promotion := range promotions {
+				moves = append(moves, FourPlayerMove{From: from, To: to, Promotion: promotion})
+			}
+			return
+		}
+		moves = append(moves, FourPlayerMove{From: from, To: to})
+	}
+
+	walkBetza(betzaOf(piece.Type), from, int(piece.Player), g.OnBoard, g.occupied, func(to Space, quiet, capture bool) bool {
+		if other, ok := g.PieceAt(to); quiet || capture && ok && other.Player != piece.Player {
+			add(to)
+		}
+		return true
+	})
+
+	// pawns on their second rank may move two spaces up
+	if piece.Type == PiecePawn && fourPlayerRelative(piece.Player, from).Rank == 1 {
+		relative := fourPlayerRelative(piece.Player, from)
+		one := fourPlayerAbsolute(piece.Player, Space{File: relative.File, Rank: 2})
+		two := fourPlayerAbsolute(piece.Player, Space{File: relative.File, Rank: 3})
+		if !g.occupied(one) && !g.occupied(two) {
+			add(two)
+		}
+	}
+	return moves
+}
+
+// attacks returns if any piece of by attacks s.
+func (g *FourPlayerGame) attacks(by Player, s Space) bool {
+	for _, attacker := range g.Pieces(by) {
+		reached := !walkBetza(betzaOf(attacker.Type), attacker.Location, int(by), g.OnBoard, g.occupied, func(to Space, quiet, capture bool) bool {
+			return !capture || to != s
+		})
+		if reached {
+			return true
+		}
+	}
+	return false
+}
+
+// attacked returns if any player other than p, who has
+// not been eliminated, attacks s.
+func (g *FourPlayerGame) attacked(s Space, p Player) bool {
+	for other := p.next(); other != p; other = other.next() {
+		if !g.Eliminated[other] && g.attacks(other, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// InCheck returns if p's king is attacked by another player.
+func (g *FourPlayerGame) InCheck(p Player) bool {
+	for _, king := range g.Pieces(p) {
+		if king.Type == PieceKing && g.attacked(king.Location, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// LegalMoves returns all of the legal moves for the player whose turn it is.
+func (g *FourPlayerGame) LegalMoves() []FourPlayerMove {
+	if g.Completion.Done {
+		return nil
+	}
+	return g.legalMoves(g.turn)
+}
+
+// legalMoves returns the moves of p which do not leave p's king attacked.
+func (g *FourPlayerGame) legalMoves(p Player) []FourPlayerMove {
+	var moves []FourPlayerMove
+	for _, piece := range g.Pieces(p) {
+		for _, mv := range g.pseudoMoves(piece.Location) {
+			after := *g
+			after.History = nil
+			after.move(mv)
+			if !after.InCheck(p) {
+				moves = append(moves, mv)
+			}
+		}
+	}
+	return moves
+}
+
+// move moves the pieces of mv, and returns the piece that it captured.
+func (g *FourPlayerGame) move(mv FourPlayerMove) fourPlayerSquare {
+	from, to := g.square(mv.From), g.square(mv.To)
+	captured := g.board[to]
+	g.board[to] = g.board[from]
+	g.board[from] = fourPlayerSquare{}
+	if mv.Promotion != PieceNone {
+		g.board[to].t = mv.Promotion
+	}
+	return captured
+}
+
+// MakeMove makes a move for the player whose turn it is, or returns
+// an error if the move is not legal. Turns then pass to the next player
+// who has not been eliminated, eliminating any players who are checkmated
+// or stalemated along the way.
+func (g *FourPlayerGame) MakeMove(m FourPlayerMove) error {
+	if g.Completion.Done {
+		return ErrGameOver
+	}
+
+	var legal bool
+	for _, mv := range g.LegalMoves() {
+		legal = legal || mv == m
+	}
+	if !legal {
+		return errors.New("cannot move from " + m.From.String() + " to " + m.To.String() + ": move is not legal for " + g.turn.String())
+	}
+
+	mover := g.turn
+	captured := g.move(m)
+	if captured.t != PieceNone && !g.Eliminated[captured.player] {
+		if int(captured.t) < len(fourPlayerPoints) {
+			g.Scores[mover] += fourPlayerPoints[captured.t]
+		} else {
+			g.Scores[mover] += fourPlayerPoints[PieceQueen]
+		}
+		if captured.t == PieceKing {
+			g.Eliminated[captured.player] = true
+		}
+	}
+	g.History = append(g.History, m)
+
+	g.nextTurn()
+	return nil
+}
+
+// Resign eliminates p from the game. If it is p's turn,
+// the turn passes to the next player.
+func (g *FourPlayerGame) Resign(p Player) error {
+	if g.Completion.Done || g.Eliminated[p] {
+		return ErrGameOver
+	}
+
+	g.Eliminated[p] = true
+	if g.turn == p {
+		g.nextTurn()
+	} else {
+		g.updateCompletion()
+	}
+	return nil
+}
+
+// nextTurn passes the turn to the next player who has not been
+// eliminated and is able to move, eliminating each player before them
+// who cannot. A checkmated player's points go to the last player before
+// them who is checking their king, and a stalemated player keeps them.
+func (g *FourPlayerGame) nextTurn() {
+	for {
+		g.updateCompletion()
+		if g.Completion.Done {
+			return
+		}
+
+		g.turn = g.turn.next()
+		if g.Eliminated[g.turn] {
+			continue
+		}
+		if len(g.legalMoves(g.turn)) > 0 {
+			return
+		}
+
+		if g.InCheck(g.turn) {
+			g.Scores[g.checker(g.turn)] += fourPlayerMatePoints
+		} else {
+			g.Scores[g.turn] += fourPlayerMatePoints
+		}
+		g.Eliminated[g.turn] = true
+	}
+}
+
+// checker returns the player who is checking p's king, preferring the
+// player who moved most recently if more than one player is.
+func (g *FourPlayerGame) checker(p Player) Player {
+	var king Space
+	for _, piece := range g.Pieces(p) {
+		if piece.Type == PieceKing {
+			king = piece.Location
+		}
+	}
+
+	checker := p
+	for other := p.next(); other != p; other = other.next() {
+		if !g.Eliminated[other] && g.attacks(other, king) {
+			checker = other
+		}
+	}
+	return checker
+}
+
+// updateCompletion ends the game once only one player is left.
+func (g *FourPlayerGame) updateCompletion() {
+	var left int
+	for _, eliminated := range g.Eliminated {
+		if !eliminated {
+			left++
+		}
+	}
+	if left > 1 {
+		return
+	}
+
+	g.Completion = FourPlayerCompletion{Done: true}
+	for p := PlayerRed; p <= PlayerGreen; p++ {
+		switch {
+		case g.Scores[p] > g.Scores[g.Completion.Winner]:
+			g.Completion.Winner, g.Completion.Draw = p, false
+		case g.Scores[p] == g.Scores[g.Completion.Winner] && p != g.Completion.Winner:
+			g.Completion.Draw = true
+		}
+	}
+}
@@ -1,21 +1,26 @@
 package chess
 
-type castlingRights struct {
-	BlackKing, BlackQueen bool
-	WhiteKing, WhiteQueen bool
-}
-
-// CompletionState represents the current completion state of the board
-type CompletionState struct {
-	Done   bool
-	Draw   bool
-	Winner Color
-}
-
 // Game represents a game of chess
 type Game struct {
-	// stored in [file][rank] form
-	board [8][8]Piece
+	// bitboards of each color's pieces, indexed by colorIndex
+	colors [2]Bitboard
+
+	// bitboards of each type of piece, indexed by PieceType
+	types [pieceTypes]Bitboard
+
+	// the type of piece on each square, for quick lookups
+	mailbox [maxSquares]PieceType
+
+	// the number of files and ranks on the board, or 0 for
+	// an 8x8 board. boards that are not 8x8 do not fit in
+	// colors and types, and use whites and the mailbox instead.
+	files, ranks int
+
+	// the squares of white's pieces on boards that are not 8x8
+	whites squareSet
+
+	// the zobrist hash of the pieces on the board
+	hash uint64
 
 	// which castles are still possible
 	Castles castlingRights
@@ -31,48 +36,90 @@ type Game struct {
 
 	// the current completion state
 	Completion CompletionState
+
+	// the number of checks that each player has given,
+	// which are only counted in variants such as Three-check
+	Checks checkCounts
+
+	// the pieces that each player may drop onto the
+	// board, in variants such as Crazyhouse
+	Pockets pockets
+
+	// the pieces on the board which were promoted from pawns, which
+	// are only tracked in variants where it matters, such as Crazyhouse
+	Promoted Bitboard
+
+	// a bitboard holding the space of the duck in Duck Chess, which is a
+	// blocker that no piece may move onto, capture or move through. it is
+	// empty until the duck is first placed.
+	Duck Bitboard
+
+	// the variant that the game is played by, or
+	// nil if it is played by standard chess
+	variant Variant
+
+	// if a draw has been offered, and who offered it
+	drawOffered   bool
+	drawOfferedBy Color
+
+	// the moves made so far, and the moves which
+	// have been undone and may be redone
+	history []undo
+	redo    []move
 }
 
 // Clone returns a new instance of `g`.
 func (g *Game) Clone() *Game {
-	var newG = &Game{
-		board:     g.board,
-		EnPassant: g.EnPassant,
-		Castles:   g.Castles,
-		Halfmove:  g.Halfmove,
-	}
-	for i, file := range g.board {
-		for j, piece := range file {
-			newPiece := piece
-			newPiece.Game = newG
-			newG.board[i][j] = newPiece
-		}
-	}
-	return newG
+	newG := *g
+	newG.history = append([]undo(nil), g.history...)
+	newG.redo = append([]move(nil), g.redo...)
+	return &newG
 }
 
 // BoardFileRank returns the game board in it's current state.
 // Access board contents with [file][rank]. Useful for determining
-// the position of pieces.
+// the position of pieces. It is empty for boards that are not 8x8,
+// which are returned by Board.
 func (g *Game) BoardFileRank() [8][8]Piece {
-	return g.board
+	var board [8][8]Piece
+
+	occupied := g.occupied()
+	for occupied != 0 {
+		sq := occupied.pop()
+		s := spaceOf(sq)
+		board[s.File][s.Rank] = g.pieceOn(sq)
+	}
+
+	return board
 }
 
 // BoardRankFile returns the game board in it's current state.
 // Access board contents with [rank][file]. Useful for printing
-// the board rank-by-rank.
+// the board rank-by-rank. It is empty for boards that are not 8x8,
+// which are returned by Board.
 func (g *Game) BoardRankFile() [8][8]Piece {
 	var board [8][8]Piece
 
-	for i, rank := range g.board {
-		for j, piece := range rank {
-			board[j][i] = piece
-		}
+	occupied := g.occupied()
+	for occupied != 0 {
+		sq := occupied.pop()
+		s := spaceOf(sq)
+		board[s.Rank][s.File] = g.pieceOn(sq)
 	}
 
 	return board
 }
 
+// Bitboard returns the spaces of c's pieces with PieceType t.
+// If t is PieceNone, all of c's pieces are returned. It is
+// empty for boards that are not 8x8.
+func (g *Game) Bitboard(c Color, t PieceType) Bitboard {
+	if t == PieceNone {
+		return g.colors[colorIndex(c)]
+	}
+	return g.colors[colorIndex(c)] & g.types[t]
+}
+
 // Turn returns who should move next.
 func (g *Game) Turn() Color {
 	return g.Fullmove%2 == 0
@@ -80,63 +127,93 @@ func (g *Game) Turn() Color {
 
 // TypedAlivePieces returns all of c's alive pieces with PieceType t.
 func (g *Game) TypedAlivePieces(c Color, t PieceType) []Piece {
-	pieces := make([]Piece, 0, 16)
-
-	for _, p := range g.AlivePieces(c) {
-		if p.Type == t {
-			pieces = append(pieces, p)
+	if !g.bitboards() {
+		var pieces []Piece
+		for _, sq := range g.squaresOf(c) {
+			if t == PieceNone || g.mailbox[sq] == t {
+				pieces = append(pieces, g.pieceOn(sq))
+			}
 		}
+		return pieces
 	}
-
-	return pieces
+	return g.piecesOn(g.Bitboard(c, t))
 }
 
 // AlivePieces returns the pieces that c has on the board.
 func (g *Game) AlivePieces(c Color) []Piece {
-	pieces := make([]Piece, 0, 16)
+	return g.TypedAlivePieces(c, PieceNone)
+}
 
-	for _, file := range g.board {
-		for _, piece := range file {
-			if piece.Type != PieceNone && piece.Color == c {
-				pieces = append(pieces, piece)
-			}
-		}
+func (g *Game) piecesOn(b Bitboard) []Piece {
+	pieces := make([]Piece, 0, b.Count())
+	for b != 0 {
+		pieces = append(pieces, g.pieceOn(b.pop()))
 	}
-
 	return pieces
 }
 
+// pieceOn returns the piece on sq.
+func (g *Game) pieceOn(sq int) Piece {
+	return Piece{
+		Game:     g,
+		Type:     g.mailbox[sq],
+		Location: g.space(sq),
+		Color:    g.colorAt(sq),
+	}
+}
+
+// LegalMoves returns all of the legal moves for the player
+// whose turn it is.
+func (g *Game) LegalMoves() []Move {
+	internal := g.legalMoves(g.Turn(), nil)
+
+	moves := make([]Move, len(internal))
+	for i, mv := range internal {
+		moves[i] = g.fullMove(mv)
+	}
+	return moves
+}
+
 // PieceAt returns the piece at a given space, and an `ok`
 // boolean on if there was a piece on that space at all.
 func (g *Game) PieceAt(s Space) (Piece, bool) {
-	if !s.Valid() {
+	if !g.OnBoard(s) {
 		return Piece{}, false
 	}
 
-	piece := g.board[s.File][s.Rank]
-	if piece.Type == PieceNone {
+	sq := g.square(s)
+	if g.mailbox[sq] == PieceNone {
 		return Piece{}, false
 	}
 
-	return piece, true
+	return g.pieceOn(sq), true
 }
 
 // InCheck returns if `c` is in check.
 func (g *Game) InCheck(c Color) bool {
+	if g.variant != nil {
+		return g.variant.InCheck(g, c)
+	}
+	return g.inCheck(c)
+}
 
-	king := g.TypedAlivePieces(c, PieceKing)[0]
-
-	oPieces := g.AlivePieces(c.Other())
-	for _, piece := range oPieces {
-		seeing := piece.Seeing()
-		for _, sees := range seeing {
-			if sees == king.Location {
-				return true
+// inCheck returns if c's king is attacked.
+func (g *Game) inCheck(c Color) bool {
+	if !g.bitboards() {
+		for _, sq := range g.squaresOf(c) {
+			if g.mailbox[sq] == PieceKing {
+				return g.attacked(sq, c.Other())
 			}
 		}
+		return false
 	}
 
-	return false
+	king := g.Bitboard(c, PieceKing)
+	if king == 0 {
+		return false
+	}
+
+	return g.attacked(king.lowest(), c.Other())
 }
 
 // InCheckmate returns if `c` is in checkmate.
@@ -154,131 +231,30 @@ func (g *Game) InStalemate(c Color) bool {
 // (to draw via threefold repetition, the last position played
 // must have been played at least 2 other times).
 func (g *Game) CanDraw() bool {
-	if g.Fullmove < 5 {
-		return false
-	}
-
-	// 50 move rule
-	if g.Halfmove >= 50 && !g.InCheckmate(g.Turn()) {
-		return true
-	}
-
-	// insufficient material... oh no
-	whiteAlive := g.AlivePieces(White)
-	blackAlive := g.AlivePieces(Black)
-
-	// king vs king
-	if len(whiteAlive) == 1 && len(blackAlive) == 1 {
-		return true
-	}
-
-	// king/(bishop|knight) vs king
-	if len(whiteAlive) == 2 && len(blackAlive) == 1 {
-		switch {
-		case whiteAlive[0].Type == PieceBishop,
-			whiteAlive[0].Type == PieceKnight,
-			whiteAlive[1].Type == PieceBishop,
-			whiteAlive[1].Type == PieceKnight:
-
-			return true
-		}
-	}
-	if len(whiteAlive) == 1 && len(blackAlive) == 2 {
-		switch {
-		case blackAlive[0].Type == PieceBishop,
-			blackAlive[0].Type == PieceKnight,
-			blackAlive[1].Type == PieceBishop,
-			blackAlive[1].Type == PieceKnight:
-
-			return true
-		}
-	}
-
-	// if all that exist are bishops on the same color...
-	var bishops int
-	var bishopColor Color
-
-	// white bishops
-	for _, piece := range whiteAlive {
-		if piece.Type == PieceBishop {
-			if bishops == 0 {
-				bishopColor = piece.Location.Color()
-			} else {
-				if bishopColor != piece.Location.Color() {
-					break
-				}
-			}
-			bishops++
-		}
-	}
-	if bishops != len(whiteAlive)-1 {
-		return false
-	}
-
-	bishops = 0
-	// black bishops
-	for _, piece := range blackAlive {
-		if piece.Type == PieceBishop {
-			if bishops == 0 {
-				bishopColor = piece.Location.Color()
-			} else {
-				if bishopColor != piece.Location.Color() {
-					break
-				}
-			}
-			bishops++
-		}
-	}
-	if bishops != len(blackAlive)-1 {
-		return false
-	}
-
-	return true
+	return g.claimableDraw() != TerminationNone || g.InsufficientMaterial() != MaterialSufficient
 }
 
 // MakeMoveUnconditionally makes a move regardless
-// of if it should be allowed or not.
+// of if it should be allowed or not. This includes moving
+// the rook when castling, and updating the move counters.
 func (g *Game) MakeMoveUnconditionally(m Move) {
+	g.record(g.internalMove(m))
+	g.redo = g.redo[:0]
+}
 
-	var target *Piece
-	target = &g.board[m.To.File][m.To.Rank]
-
-	// update piece
-	*target = m.Moving
-	target.Location = m.To
-
-	// update piece type for promotions
-	if m.Promotion != PieceNone {
-		target.Type = m.Promotion
-	}
-
-	// handle en passant
-	if m.Moving.Type == PiecePawn && m.To == g.EnPassant {
-		deadSpace := Space{File: m.To.File, Rank: m.Moving.Location.Rank}
-		g.board[deadSpace.File][deadSpace.Rank] = Piece{}
-	}
+// record makes mv and adds it to the game's history.
+func (g *Game) record(mv move) {
+	position := g.PositionKey()
+	u := g.makeMove(mv)
+	u.position = position
+	g.history = append(g.history, u)
+}
 
-	// update where piece came from
-	from := m.Moving.Location
-	g.board[from.File][from.Rank] = Piece{}
-
-	// update castling rights
-	switch m.Moving.Location {
-	case Space{File: 0, Rank: 0}:
-		g.Castles.WhiteQueen = false
-	case Space{File: 7, Rank: 0}:
-		g.Castles.WhiteKing = false
-	case Space{File: 0, Rank: 7}:
-		g.Castles.BlackQueen = false
-	case Space{File: 7, Rank: 7}:
-		g.Castles.BlackKing = false
-	case Space{File: 4, Rank: 0}:
-		g.Castles.WhiteKing = false
-		g.Castles.WhiteQueen = false
-	case Space{File: 4, Rank: 7}:
-		g.Castles.BlackKing = false
-		g.Castles.BlackQueen = false
-	}
+// hasEnPassant returns if g.EnPassant refers to an actual space.
+// En passant targets are never on the first rank, so the zero
+// value of Space means that there is no target.
+func (g *Game) hasEnPassant() bool {
+	return g.EnPassant.Rank != 0
 }
 
 // MakeMove makes a move in the game, or returns an error if the move is not possible.
@@ -297,23 +273,64 @@ func (g *Game) MakeMove(m Move) error {
 			Reason: "it is " + g.Turn().String() + "'s turn",
 		}
 	}
-	// check if the move is valid
-	var validMove bool
-	seeing := m.Moving.Seeing()
-	for _, s := range seeing {
-		if m.To == s {
-			validMove = true
-			break
+
+	if _, ok := g.variant.(Duck); ok && !m.HasDuck {
+		return &MoveError{
+			Cause:  m,
+			Reason: "must specify where to move the duck",
+		}
+	}
+
+	var legalMoves []move
+	if m.Drop {
+		legalMoves = g.legalMoves(m.Moving.Color, nil)
+	} else {
+		if piece, ok := g.PieceAt(m.Moving.Location); !ok || piece.Type != m.Moving.Type || piece.Color != m.Moving.Color {
+			return &MoveError{
+				Cause:  m,
+				Reason: "piece is not on the board",
+			}
+		}
+		legalMoves = g.legalMovesFrom(g.square(m.Moving.Location), nil)
+	}
+
+	mv := g.internalMove(m)
+	for _, legal := range legalMoves {
+		if legal == mv {
+			g.MakeMoveUnconditionally(m)
+			g.updateCompletion()
+
+			// moving declines the opponent's draw offer
+			if g.drawOffered && g.drawOfferedBy != m.Moving.Color {
+				g.drawOffered = false
+			}
+			return nil
 		}
 	}
-	if !validMove {
+
+	// find out why the move is not legal
+	if m.Drop {
+		if g.Pockets.Of(m.Moving.Color)[m.Moving.Type] == 0 {
+			return &MoveError{
+				Cause:  m,
+				Reason: "there is no " + m.Moving.Type.String() + " to drop",
+			}
+		}
+		return &MoveError{
+			Cause:   m,
+			Reason:  "piece cannot be dropped there",
+			InCheck: g.InCheck(m.Moving.Color),
+		}
+	}
+
+	if !g.sees(m.Moving, m.To) {
 		return &MoveError{
 			Cause:  m,
 			Reason: "piece cannot see space",
 		}
 	}
 
-	if m.Moving.Type == PiecePawn && (m.To.Rank == 0 || m.To.Rank == 7) {
+	if _, ranks := g.Size(); m.Moving.Type == PiecePawn && (m.To.Rank == 0 || m.To.Rank == ranks-1) {
 		switch m.Promotion {
 		case PieceRook, PieceKnight, PieceBishop, PieceQueen:
 			break
@@ -335,100 +352,56 @@ func (g *Game) MakeMove(m Move) error {
 		}
 	}
 
-	var legal bool
-	legalMoves := m.Moving.LegalMoves()
-	for _, s := range legalMoves {
-		if m.To == s {
-			legal = true
-			break
-		}
-	}
-	if !legal {
+	if g.variant != nil {
 		return &MoveError{
 			Cause:   m,
-			Reason:  "player is in check",
-			InCheck: true,
-		}
-	}
-
-	other := g.Turn().Other()
-	oldAlivePieces := len(g.AlivePieces(other))
-
-	// make the move
-	g.MakeMoveUnconditionally(m)
-
-	// move rook in castles
-	if m.Moving.Type == PieceKing {
-		diff := m.Moving.Location.File - m.To.File
-
-		if diff == -2 {
-			rook, _ := g.PieceAt(Space{File: 0, Rank: m.To.Rank})
-			g.MakeMoveUnconditionally(Move{
-				Moving: rook,
-				To:     Space{File: 3, Rank: m.To.Rank},
-			})
+			Reason:  "move is not allowed in " + g.variant.Name(),
+			InCheck: g.InCheck(m.Moving.Color),
 		}
-		if diff == 2 {
-			rook, _ := g.PieceAt(Space{File: 7, Rank: m.To.Rank})
-			g.MakeMoveUnconditionally(Move{
-				Moving: rook,
-				To:     Space{File: 5, Rank: m.To.Rank},
-			})
-		}
-	}
-
-	// update move counts
-
-	g.Fullmove++
-	if len(g.AlivePieces(other)) < oldAlivePieces || m.Moving.Type == PiecePawn {
-		g.Halfmove = 0
-	} else {
-		g.Halfmove++
-	}
-
-	// check completion state
-	if g.InCheckmate(g.Turn()) {
-		g.Completion.Done = true
-		g.Completion.Winner = g.Turn().Other()
 	}
-	if g.InStalemate(g.Turn()) {
-		g.Completion.Done = true
-		g.Completion.Draw = true
+	return &MoveError{
+		Cause:   m,
+		Reason:  "player is in check",
+		InCheck: true,
 	}
+}
 
-	return nil
+// updateCompletion checks if the game has ended after a move.
+func (g *Game) updateCompletion() {
+	g.Completion = g.Variant().Completion(g)
 }
 
 func (g *Game) canMove(c Color) bool {
-	for _, piece := range g.AlivePieces(c) {
-		if len(piece.LegalMoves()) > 0 {
+	if g.variant != nil {
+		return len(g.variant.Moves(g, c, nil)) > 0
+	}
+
+	for _, sq := range g.squaresOf(c) {
+		if len(g.legalMovesFrom(sq, nil)) > 0 {
 			return true
 		}
 	}
 	return false
 }
 
-func slicesEqual(a []Space, b []Space) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
+// InitCustom initializes g to a custom chess layout, keeping
+// its variant. The pieces are stored in [file][rank] form.
+func (g *Game) InitCustom(pieces [8][8]Piece) {
+	*g = Game{variant: g.variant}
+	g.Castles.WhiteFiles = ClassicCastleFiles
+	g.Castles.BlackFiles = ClassicCastleFiles
+	for file, pieceFile := range pieces {
+		for rank, piece := range pieceFile {
+			if piece.Type != PieceNone {
+				g.put(squareOf(Space{File: file, Rank: rank}), piece.Type, piece.Color)
+			}
 		}
 	}
-
-	return true
-}
-
-// InitCustom initializes g to a custom chess layout
-func (g *Game) InitCustom(pieces [8][8]Piece) {
-	*g = Game{board: pieces}
 }
 
-// InitClassic initializes g to a classic chess layout,
+// InitClassic initializes g to a classic chess layout, keeping its variant.
 func (g *Game) InitClassic() {
-	*g = Game{}
+	*g = Game{variant: g.variant, Castles: classicCastlingRights}
 	rank := [8]PieceType{
 		PieceRook,
 		PieceKnight,
@@ -440,19 +413,10 @@ func (g *Game) InitClassic() {
 		PieceRook,
 	}
 	for i, pieceType := range rank {
-		putPiece(g, pieceType, White, Space{File: i, Rank: 0})
-		putPiece(g, PiecePawn, White, Space{File: i, Rank: 1})
+		g.put(squareOf(Space{File: i, Rank: 0}), pieceType, White)
+		g.put(squareOf(Space{File: i, Rank: 1}), PiecePawn, White)
 
-		putPiece(g, pieceType, Black, Space{File: i, Rank: 7})
-		putPiece(g, PiecePawn, Black, Space{File: i, Rank: 6})
-	}
-}
-
-func putPiece(g *Game, p PieceType, c Color, s Space) {
-	g.board[s.File][s.Rank] = Piece{
-		Game:     g,
-		Type:     p,
-		Location: s,
-		Color:    c,
+		g.put(squareOf(Space{File: i, Rank: 7}), pieceType, Black)
+		g.put(squareOf(Space{File: i, Rank: 6}), PiecePawn, Black)
 	}
 }
@@ -0,0 +1,129 @@
+package chess
+
+// GrandChess is the Variant played on a 10x10 board, where each player
+// also has an archbishop and a chancellor, and there is no castling.
+// Pawns start on the third rank, and may promote on the eighth and
+// ninth ranks, and must promote on the last rank. A pawn may only
+// promote to a piece that its player has lost, so a pawn cannot move
+// to the last rank while none of its player's pieces have been captured.
+type GrandChess struct {
+	Standard
+}
+
+// Name returns "Grand Chess".
+func (GrandChess) Name() string {
+	return "Grand Chess"
+}
+
+// registerPieces registers the archbishop and chancellor.
+func (GrandChess) registerPieces() {
+	capablancaPieces()
+}
+
+// Init sets up the rooks in the corners, the rest of the pieces on the
+// second rank, and the pawns on the third rank.
+func (GrandChess) Init(g *Game) {
+	archbishop, chancellor := capablancaPieces()
+	second := []PieceType{PieceNone, PieceKnight, PieceBishop, PieceQueen, PieceKing, chancellor, archbishop, PieceBishop, PieceKnight, PieceNone}
+	board := make([][]Piece, len(second))
+	for file, t := range second {
+		board[file] = make([]Piece, 10)
+		if file == 0 || file == 9 {
+			board[file][0] = Piece{Type: PieceRook, Color: White}
+			board[file][9] = Piece{Type: PieceRook, Color: Black}
+		}
+		board[file][1] = Piece{Type: t, Color: White}
+		board[file][2] = Piece{Type: PiecePawn, Color: White}
+		board[file][7] = Piece{Type: PiecePawn, Color: Black}
+		board[file][8] = Piece{Type: t, Color: Black}
+	}
+
+	g.InitCustomBoard(board)
+}
+
+// Moves appends all of c's legal moves to moves, including pawns moving
+// two spaces up from the third rank and promoting on the last three ranks.
+func (GrandChess) Moves(g *Game, c Color, moves []Move) []Move {
+	_, ranks := g.Size()
+	available := grandPromotions(g, c)
+
+	for _, mv := range append(g.standardMoves(c, nil), grandDoubleSteps(g, c)...) {
+		rank := g.space(mv.to).Rank
+		if c == Black {
+			rank = ranks - 1 - rank
+		}
+		if g.mailbox[mv.from] != PiecePawn || rank < ranks-3 {
+			moves = append(moves, g.moveOf(mv))
+			continue
+		}
+
+		if rank == ranks-1 {
+			// the move generator gives a promotion to each
+			// standard piece, which are replaced by the
+			// pieces that c is able to promote to
+			if mv.promotion != PieceQueen {
+				continue
+			}
+		} else {
+			moves = append(moves, g.moveOf(mv))
+		}
+		for _, t := range available {
+			mv.promotion = t
+			moves = append(moves, g.moveOf(mv))
+		}
+	}
+	return moves
+}
+
+// grandDoubleSteps returns c's legal moves of pawns
+// two spaces up from their third rank.
+func grandDoubleSteps(g *Game, c Color) []move {
+	_, ranks := g.Size()
+	forward, third := 1, 2
+	if c == Black {
+		forward, third = -1, ranks-3
+	}
+
+	var moves []move
+	for _, pawn := range g.TypedAlivePieces(c, PiecePawn) {
+		one := Space{File: pawn.Location.File, Rank: pawn.Location.Rank + forward}
+		two := Space{File: pawn.Location.File, Rank: pawn.Location.Rank + 2*forward}
+		if pawn.Location.Rank != third || g.occupiedAt(one) || g.occupiedAt(two) {
+			continue
+		}
+
+		mv := move{from: g.square(pawn.Location), to: g.square(two)}
+		u := g.makeMove(mv)
+		inCheck := g.inCheck(c)
+		g.unmakeMove(u)
+		if !inCheck {
+			moves = append(moves, mv)
+		}
+	}
+	return moves
+}
+
+// grandPromotions returns the pieces that c's pawns may promote
+// to, which are the pieces that c has fewer of than at the start.
+func grandPromotions(g *Game, c Color) []PieceType {
+	archbishop, chancellor := capablancaPieces()
+	start := [...]struct {
+		t PieceType
+		n int
+	}{
+		{PieceQueen, 1},
+		{chancellor, 1},
+		{archbishop, 1},
+		{PieceRook, 2},
+		{PieceBishop, 2},
+		{PieceKnight, 2},
+	}
+
+	var available []PieceType
+	for _, piece := range start {
+		if len(g.TypedAlivePieces(c, piece.t)) < piece.n {
+			available = append(available, piece.t)
+		}
+	}
+	return available
+}
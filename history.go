@@ -0,0 +1,96 @@
+package chess
+
+// UnmakeMove takes back the last move made in the game, restoring
+// the position, castling rights, en passant target, move counters,
+// and completion state to what they were before the move. Unlike
+// Undo, the move cannot be redone. Any standing draw offer is
+// withdrawn.
+func (g *Game) UnmakeMove() error {
+	if len(g.history) == 0 {
+		return ErrNoUndo
+	}
+	g.drawOffered = false
+
+	last := len(g.history) - 1
+	g.unmakeMove(g.history[last])
+	g.history = g.history[:last]
+	g.redo = g.redo[:0]
+
+	return nil
+}
+
+// Undo takes back the last n moves made in the game. Undone
+// moves can be made again with Redo until another move is made.
+// Any standing draw offer is withdrawn.
+func (g *Game) Undo(n int) error {
+	if n > len(g.history) {
+		return ErrNoUndo
+	}
+	if n > 0 {
+		g.drawOffered = false
+	}
+
+	for i := 0; i < n; i++ {
+		last := len(g.history) - 1
+		g.unmakeMove(g.history[last])
+		g.redo = append(g.redo, g.history[last].move)
+		g.history = g.history[:last]
+	}
+
+	return nil
+}
+
+// Redo makes the last n moves that were taken back with Undo.
+func (g *Game) Redo(n int) error {
+	if n > len(g.redo) {
+		return ErrNoRedo
+	}
+
+	for i := 0; i < n; i++ {
+		last := len(g.redo) - 1
+		g.record(g.redo[last])
+		g.redo = g.redo[:last]
+		g.updateCompletion()
+	}
+
+	return nil
+}
+
+// CanUndo returns the number of moves that can be taken back.
+func (g *Game) CanUndo() int {
+	return len(g.history)
+}
+
+// CanRedo returns the number of undone moves that can be redone.
+func (g *Game) CanRedo() int {
+	return len(g.redo)
+}
+
+// dropper is implemented by variants where captured pieces may be
+// dropped back onto the board, such as Crazyhouse.
+type dropper interface {
+	dropsCaptures()
+}
+
+// RepetitionCount returns the number of times that the current
+// position has occurred in the game, including the current
+// occurrence. Positions are compared by their PositionKey.
+func (g *Game) RepetitionCount() int {
+	key := g.PositionKey()
+	count := 1
+
+	// positions from before the last capture or pawn move are
+	// never able to be repeated, unless captured pieces are able
+	// to be dropped back onto the board
+	oldest := len(g.history) - g.Halfmove
+	if _, ok := g.variant.(dropper); ok {
+		oldest = 0
+	}
+	for i := len(g.history) - 1; i >= 0 && i >= oldest; i-- {
+		if g.history[i].position == key {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,98 @@
+package chess
+
+// Horde is the Variant where white starts with 36 pawns and no king, and
+// black wins by capturing every white piece. Pawns on the first rank may
+// also move two spaces up.
+type Horde struct {
+	Standard
+}
+
+// Name returns "Horde".
+func (Horde) Name() string {
+	return "Horde"
+}
+
+// Init sets up the horde of white pawns against black's classic layout.
+func (Horde) Init(g *Game) {
+	var board [8][8]Piece
+	back := [8]PieceType{PieceRook, PieceKnight, PieceBishop, PieceQueen, PieceKing, PieceBishop, PieceKnight, PieceRook}
+	for file := 0; file < 8; file++ {
+		board[file][7] = Piece{Type: back[file], Color: Black}
+		board[file][6] = Piece{Type: PiecePawn, Color: Black}
+		for rank := 0; rank < 4; rank++ {
+			board[file][rank] = Piece{Type: PiecePawn, Color: White}
+		}
+		if file != 0 && file != 3 && file != 4 && file != 7 {
+			board[file][4] = Piece{Type: PiecePawn, Color: White}
+		}
+	}
+
+	g.InitCustom(board)
+	g.Castles.Set(Black, true, true)
+	g.Castles.Set(Black, false, true)
+}
+
+// Seeing returns the spaces that p can see, which
+// include two spaces up for pawns on the first rank.
+func (Horde) Seeing(g *Game, p Piece) Bitboard {
+	return Standard{}.Seeing(g, p) | g.firstRankDoubleStep(squareOf(p.Location))
+}
+
+// Moves appends all of c's legal moves to moves,
+// including pawns moving two spaces up from the first rank.
+func (Horde) Moves(g *Game, c Color, moves []Move) []Move {
+	moves = Standard{}.Moves(g, c, moves)
+
+	firstRank := Bitboard(0xFF)
+	if c == Black {
+		firstRank = 0xFF00000000000000
+	}
+	for pawns := g.Bitboard(c, PiecePawn) & firstRank; pawns != 0; {
+		from := pawns.pop()
+		to := g.firstRankDoubleStep(from)
+		if to == 0 {
+			continue
+		}
+
+		mv := move{from: from, to: to.lowest()}
+		u := g.makeMove(mv)
+		inCheck := g.inCheck(c)
+		g.unmakeMove(u)
+		if !inCheck {
+			moves = append(moves, g.moveOf(mv))
+		}
+	}
+	return moves
+}
+
+// firstRankDoubleStep returns the space two spaces up from the pawn
+// on sq if it is on its player's first rank and can move there.
+func (g *Game) firstRankDoubleStep(sq int) Bitboard {
+	if g.mailbox[sq] != PiecePawn {
+		return 0
+	}
+
+	forward, firstRank := 8, 0
+	if g.colorAt(sq) == Black {
+		forward, firstRank = -8, 7
+	}
+	if sq/8 != firstRank || g.occupied()&(squareBit(sq+forward)|squareBit(sq+2*forward)) != 0 {
+		return 0
+	}
+	return squareBit(sq + 2*forward)
+}
+
+// Completion ends the game once white has no pieces left, or by the
+// standard rules. The game is not drawn by insufficient material, since
+// the standard cases assume that both players have a king.
+func (Horde) Completion(g *Game) CompletionState {
+	switch {
+	case g.colors[colorIndex(White)] == 0:
+		return CompletionState{Done: true, Winner: Black, Reason: TerminationHordeCaptured}
+	case g.InCheckmate(g.Turn()):
+		return CompletionState{Done: true, Winner: g.Turn().Other(), Reason: TerminationCheckmate}
+	case g.InStalemate(g.Turn()):
+		return CompletionState{Done: true, Draw: true, Reason: TerminationStalemate}
+	}
+	return g.automaticDraw()
+}
@@ -0,0 +1,39 @@
+package chess
+
+// the spaces that a king wins by reaching in King of the Hill: d4, e4, d5 and e5
+const hill Bitboard = 0x0000001818000000
+
+// KingOfTheHill is the Variant where a player also wins
+// by moving their king to d4, e4, d5 or e5.
+type KingOfTheHill struct {
+	Standard
+}
+
+// Name returns "King of the Hill".
+func (KingOfTheHill) Name() string {
+	return "King of the Hill"
+}
+
+// Moves appends all of c's legal moves to moves,
+// which are none once a king has reached the hill.
+func (KingOfTheHill) Moves(g *Game, c Color, moves []Move) []Move {
+	if g.types[PieceKing]&hill != 0 {
+		return moves
+	}
+	return Standard{}.Moves(g, c, moves)
+}
+
+// Completion ends the game once a king reaches the hill, or by the standard
+// rules. Since a king can always try to reach the hill, the game is
+// never drawn by insufficient material.
+func (KingOfTheHill) Completion(g *Game) CompletionState {
+	switch king := g.types[PieceKing] & hill; {
+	case king != 0:
+		return CompletionState{Done: true, Winner: g.colorAt(king.lowest()), Reason: TerminationKingOfTheHill}
+	case g.InCheckmate(g.Turn()):
+		return CompletionState{Done: true, Winner: g.Turn().Other(), Reason: TerminationCheckmate}
+	case g.InStalemate(g.Turn()):
+		return CompletionState{Done: true, Draw: true, Reason: TerminationStalemate}
+	}
+	return g.automaticDraw()
+}
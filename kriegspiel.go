@@ -0,0 +1,263 @@
+package chess
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// CheckDirection is the direction that a king is checked
+// from, as announced by the referee of a Kriegspiel game.
+type CheckDirection byte
+
+// The enum of check directions
+const (
+	CheckFile CheckDirection = iota
+	CheckRank
+
+	// the longer of the two diagonals that pass through the king
+	CheckLongDiagonal
+
+	// the shorter of the two diagonals that pass through the king
+	CheckShortDiagonal
+
+	CheckKnight
+)
+
+func (d CheckDirection) String() string {
+	return [...]string{"file", "rank", "long diagonal", "short diagonal", "knight"}[d]
+}
+
+// KriegspielAnnouncement is what the referee of a Kriegspiel
+// game announces to both players after a move attempt.
+type KriegspielAnnouncement struct {
+	// Player is the player who attempted the move.
+	Player Color
+
+	// Illegal is true if the move was not legal, in which
+	// case it was not made and Player must try another.
+	Illegal bool
+
+	// Capture is true if the move captured a piece on CaptureAt.
+	Capture   bool
+	CaptureAt Space
+
+	// Checks holds the direction of each check given by the move.
+	Checks []CheckDirection
+
+	// PawnCaptures is the number of pawn captures
+	// that the next player is able to make.
+	PawnCaptures int
+
+	// Completion is the completion state of the game after the move.
+	Completion CompletionState
+}
+
+func (a KriegspielAnnouncement) String() string {
+	if a.Illegal {
+		return "illegal"
+	}
+
+	var parts []string
+	if a.Capture {
+		parts = append(parts, "capture on "+a.CaptureAt.String())
+	}
+	for _, check := range a.Checks {
+		parts = append(parts, "check on the "+check.String())
+	}
+	if a.PawnCaptures == 1 {
+		parts = append(parts, "1 pawn capture")
+	} else if a.PawnCaptures > 1 {
+		parts = append(parts, strconv.Itoa(a.PawnCaptures)+" pawn captures")
+	}
+	if a.Completion.Done {
+		parts = append(parts, a.Completion.String())
+	}
+	if len(parts) == 0 {
+		return a.Player.String() + " has moved"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// KriegspielView is what one player of a Kriegspiel game knows: their own
+// pieces, and everything that the referee has announced.
+type KriegspielView struct {
+	Color Color
+	Turn  Color
+
+	// Pieces holds the player's pieces, which are
+	// not attached to the referee's Game.
+	Pieces []Piece
+
+	Announcements []KriegspielAnnouncement
+}
+
+// Board returns the player's pieces in [file][rank] form, with
+// the opponent's pieces left out.
+func (v KriegspielView) Board() [][]Piece {
+	board := make([][]Piece, 8)
+	for file := range board {
+		board[file] = make([]Piece, 8)
+	}
+	for _, p := range v.Pieces {
+		board[p.Location.File][p.Location.Rank] = p
+	}
+	return board
+}
+
+// Kriegspiel is a referee for a game of Kriegspiel, which is standard
+// chess where each player only sees their own pieces. Players attempt
+// moves with the referee, who makes them if they are legal and announces
+// what both players are allowed to know about them.
+type Kriegspiel struct {
+	game *Game
+
+	// Announcements holds everything the referee has
+	// announced, in the order it was announced.
+	Announcements []KriegspielAnnouncement
+}
+
+// Init sets up the game to the classic chess layout.
+func (k *Kriegspiel) Init() {
+	*k = Kriegspiel{game: &Game{}}
+	k.game.InitClassic()
+}
+
+// Game returns the referee's game, which neither player should see
+// until it is over.
+func (k *Kriegspiel) Game() *Game {
+	return k.game
+}
+
+// Turn returns the color of the player who moves next.
+func (k *Kriegspiel) Turn() Color {
+	return k.game.Turn()
+}
+
+// Completion returns the completion state of the game.
+func (k *Kriegspiel) Completion() CompletionState {
+	return k.game.Completion
+}
+
+// View returns what c knows about the game.
+func (k *Kriegspiel) View(c Color) KriegspielView {
+	view := KriegspielView{
+		Color:         c,
+		Turn:          k.game.Turn(),
+		Announcements: k.Announcements,
+	}
+	for _, p := range k.game.AlivePieces(c) {
+		p.Game = nil
+		view.Pieces = append(view.Pieces, p)
+	}
+	return view
+}
+
+// Attempt attempts to move the current player's piece on from to to.
+// Castles are attempted by moving the king, and pawns reaching the last
+// rank promote to a queen if promotion is PieceNone. If the move is not
+// legal, the announcement is "illegal" and the game does not change.
+// An error is returned if the attempt does not move one of the current
+// player's pieces, since that would not tell them anything.
+func (k *Kriegspiel) Attempt(from, to Space, promotion PieceType) (KriegspielAnnouncement, error) {
+	g := k.game
+	if g.Completion.Done {
+		return KriegspielAnnouncement{}, ErrGameOver
+	}
+
+	player := g.Turn()
+	piece, ok := g.PieceAt(from)
+	if !ok || piece.Color != player {
+		return KriegspielAnnouncement{}, errors.New("there is no " + player.String() + " piece on " + from.String())
+	}
+	if piece.Type == PiecePawn && promotion == PieceNone && (to.Rank == 0 || to.Rank == 7) {
+		promotion = PieceQueen
+	}
+
+	announcement := KriegspielAnnouncement{Player: player, Illegal: true}
+	for _, m := range piece.Moves() {
+		if m.To != to || m.Promotion != promotion {
+			continue
+		}
+
+		announcement.Illegal = false
+		if captured, ok := g.PieceAt(to); ok {
+			announcement.Capture, announcement.CaptureAt = true, captured.Location
+		} else if m.EnPassant {
+			announcement.Capture, announcement.CaptureAt = true, Space{File: to.File, Rank: from.Rank}
+		}
+		if err := g.MakeMove(m); err != nil {
+			return KriegspielAnnouncement{}, err
+		}
+
+		announcement.Checks = k.checks(player.Other())
+		announcement.PawnCaptures = k.pawnCaptures()
+		announcement.Completion = g.Completion
+		break
+	}
+
+	k.Announcements = append(k.Announcements, announcement)
+	return announcement, nil
+}
+
+// Resign ends the game with c resigning.
+func (k *Kriegspiel) Resign(c Color) error {
+	return k.game.Resign(c)
+}
+
+// checks returns the direction of each check on c's king.
+func (k *Kriegspiel) checks(c Color) []CheckDirection {
+	kings := k.game.TypedAlivePieces(c, PieceKing)
+	if len(kings) == 0 {
+		return nil
+	}
+	king := kings[0].Location
+
+	var checks []CheckDirection
+	for _, p := range k.game.AlivePieces(c.Other()) {
+		if k.game.sees(p, king) {
+			checks = append(checks, checkDirection(p.Location, king))
+		}
+	}
+	return checks
+}
+
+// checkDirection returns the direction of a check on the king on
+// king by a piece on from.
+func checkDirection(from, king Space) CheckDirection {
+	files, ranks := king.File-from.File, king.Rank-from.Rank
+	if files == ranks || files == -ranks {
+		// the number of spaces on each diagonal through the king
+		rising := 8 - abs(king.File-king.Rank)
+		falling := 8 - abs(king.File+king.Rank-7)
+		if (files == ranks) == (rising > falling) {
+			return CheckLongDiagonal
+		}
+		return CheckShortDiagonal
+	}
+
+	switch {
+	case files == 0:
+		return CheckFile
+	case ranks == 0:
+		return CheckRank
+	default:
+		return CheckKnight
+	}
+}
+
+// pawnCaptures returns the number of captures that the
+// current player's pawns are able to make.
+func (k *Kriegspiel) pawnCaptures() int {
+	var n int
+	for _, m := range k.game.LegalMoves() {
+		if m.Moving.Type != PiecePawn || m.Moving.Location.File == m.To.File {
+			continue
+		}
+		// promotions are only counted once
+		if m.Promotion == PieceNone || m.Promotion == PieceQueen {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+package chess
+
+// the light colored spaces of the board, starting with b1
+const lightSpaces Bitboard = 0x55AA55AA55AA55AA
+
+// MaterialCase represents a combination of pieces
+// that is unable to checkmate.
+type MaterialCase byte
+
+// The enum of material cases
+const (
+	// there is enough material to checkmate
+	MaterialSufficient MaterialCase = iota
+
+	// only the kings are on the board
+	MaterialKingVsKing
+
+	// a king and a bishop or knight against a lone king
+	MaterialKingMinorVsKing
+
+	// the only pieces other than the kings are bishops,
+	// and all of the bishops are on the same color
+	MaterialSameColoredBishops
+)
+
+func (m MaterialCase) String() string {
+	return [...]string{
+		"sufficient material",
+		"king vs king",
+		"king and minor piece vs king",
+		"bishops on the same color",
+	}[m]
+}
+
+// canCheckmate returns if c has enough material to checkmate by some
+// series of legal moves. c cannot if they only have their king left, or
+// if neither player can, such as with a king and knight against a king.
+func (g *Game) canCheckmate(c Color) bool {
+	if len(g.AlivePieces(c)) == len(g.TypedAlivePieces(c, PieceKing)) {
+		return false
+	}
+	return g.InsufficientMaterial() == MaterialSufficient
+}
+
+// InsufficientMaterial returns which case of insufficient material
+// applies to the game, if any. When there is insufficient material,
+// neither player is able to checkmate by any series of legal moves.
+func (g *Game) InsufficientMaterial() MaterialCase {
+	if !g.bitboards() {
+		return g.mailboxMaterial()
+	}
+
+	others := (g.colors[0] | g.colors[1]) &^ g.types[PieceKing]
+	bishops := g.types[PieceBishop]
+	minors := bishops | g.types[PieceKnight]
+
+	switch {
+	case others == 0:
+		return MaterialKingVsKing
+	case others.Count() == 1 && others&minors != 0:
+		return MaterialKingMinorVsKing
+	case others&^bishops == 0 && (others&lightSpaces == 0 || others&^lightSpaces == 0):
+		return MaterialSameColoredBishops
+	}
+
+	return MaterialSufficient
+}
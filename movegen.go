@@ -0,0 +1,517 @@
+package chess
+
+// move is the compact form of a Move that is used by the move generator.
+type move struct {
+	from, to  int
+	promotion PieceType
+	castle    bool
+	enPassant bool
+
+	// the type and color of a piece dropped from a pocket
+	// onto to, if the move is a drop. from is the same as to.
+	drop      PieceType
+	dropColor Color
+
+	// the space that the duck moves to after the move, in Duck Chess
+	duck Bitboard
+}
+
+// the pieces that a pawn may promote to
+var promotions = [...]PieceType{PieceQueen, PieceRook, PieceBishop, PieceKnight}
+
+// occupied returns every space with a piece or the duck on it.
+func (g *Game) occupied() Bitboard {
+	return g.colors[0] | g.colors[1] | g.Duck
+}
+
+// colorAt returns the color of the piece on sq.
+func (g *Game) colorAt(sq int) Color {
+	if !g.bitboards() {
+		return Color(g.whites.has(sq))
+	}
+	return g.colors[colorIndex(White)]&squareBit(sq) != 0
+}
+
+// put places a piece on sq, which must be empty.
+func (g *Game) put(sq int, t PieceType, c Color) {
+	g.mailbox[sq] = t
+	g.hash ^= zobristPieces[colorIndex(c)][t][sq]
+	if !g.bitboards() {
+		if c == White {
+			g.whites.add(sq)
+		}
+		return
+	}
+
+	bit := squareBit(sq)
+	g.colors[colorIndex(c)] |= bit
+	g.types[t] |= bit
+}
+
+// remove removes the piece on sq, if any.
+func (g *Game) remove(sq int) {
+	t := g.mailbox[sq]
+	if t == PieceNone {
+		return
+	}
+
+	g.hash ^= zobristPieces[colorIndex(g.colorAt(sq))][t][sq]
+	g.mailbox[sq] = PieceNone
+	if !g.bitboards() {
+		g.whites.remove(sq)
+		return
+	}
+
+	bit := squareBit(sq)
+	g.colors[0] &^= bit
+	g.colors[1] &^= bit
+	g.types[t] &^= bit
+}
+
+// attacks returns the spaces that a piece of type t
+// and color c on sq attacks.
+func (g *Game) attacks(t PieceType, c Color, sq int) Bitboard {
+	switch t {
+	case PiecePawn:
+		return pawnAttacks[colorIndex(c)][sq]
+	case PieceKnight:
+		return knightAttacks[sq]
+	case PieceBishop:
+		return bishopAttacks(sq, g.occupied())
+	case PieceRook:
+		return rookAttacks(sq, g.occupied())
+	case PieceQueen:
+		return bishopAttacks(sq, g.occupied()) | rookAttacks(sq, g.occupied())
+	case PieceKing:
+		return kingAttacks[sq]
+	case PieceNone:
+		return 0
+	}
+	_, attacks := g.betzaTargets(t.fairy().moves, c, sq)
+	return attacks
+}
+
+// attacked returns if any of by's pieces attack sq.
+func (g *Game) attacked(sq int, by Color) bool {
+	if !g.bitboards() {
+		return g.mailboxAttacked(sq, by)
+	}
+
+	them := g.colors[colorIndex(by)]
+	occupied := g.occupied()
+
+	// a pawn of color `by` attacks sq if sq's pawn
+	// of the other color would attack the pawn
+	if pawnAttacks[colorIndex(by.Other())][sq]&them&g.types[PiecePawn] != 0 {
+		return true
+	}
+	if knightAttacks[sq]&them&g.types[PieceKnight] != 0 {
+		return true
+	}
+	if kingAttacks[sq]&them&g.types[PieceKing] != 0 {
+		return true
+	}
+	diagonals := them & (g.types[PieceBishop] | g.types[PieceQueen])
+	if bishopAttacks(sq, occupied)&diagonals != 0 {
+		return true
+	}
+	straights := them & (g.types[PieceRook] | g.types[PieceQueen])
+	if rookAttacks(sq, occupied)&straights != 0 {
+		return true
+	}
+	return g.fairyAttacked(sq, by)
+}
+
+// pseudoTargets returns the spaces that a piece of type t and color c
+// on sq may move to, without considering if the move leaves c in check.
+func (g *Game) pseudoTargets(t PieceType, c Color, sq int) Bitboard {
+	own := g.colors[colorIndex(c)]
+	enemy := g.colors[colorIndex(c.Other())]
+
+	if t >= firstFairyPiece {
+		quiet, attacks := g.betzaTargets(t.fairy().moves, c, sq)
+		return quiet | attacks&enemy
+	}
+	if t != PiecePawn {
+		targets := g.attacks(t, c, sq) &^ (own | g.Duck)
+		if t == PieceKing {
+			targets |= g.castleTargets(c, sq)
+		}
+		return targets
+	}
+
+	var targets Bitboard
+
+	// allow moving one up if there is not a piece there,
+	// and two up if the pawn is unmoved
+	forward, startRank := 8, 1
+	if c == Black {
+		forward, startRank = -8, 6
+	}
+	occupied := g.occupied()
+	if one := sq + forward; one >= 0 && one < 64 && occupied&squareBit(one) == 0 {
+		targets |= squareBit(one)
+		if two := one + forward; sq/8 == startRank && occupied&squareBit(two) == 0 {
+			targets |= squareBit(two)
+		}
+	}
+
+	// allow diagonals if it can take, including en passant
+	captures := pawnAttacks[colorIndex(c)][sq]
+	targets |= captures & enemy
+	if g.hasEnPassant() {
+		targets |= captures & squareBit(squareOf(g.EnPassant))
+	}
+
+	return targets
+}
+
+// legalMoves appends all of c's legal moves to moves.
+func (g *Game) legalMoves(c Color, moves []move) []move {
+	if g.variant == nil {
+		return g.standardMoves(c, moves)
+	}
+	for _, m := range g.variant.Moves(g, c, nil) {
+		moves = append(moves, g.internalMove(m))
+	}
+	return moves
+}
+
+// legalMovesFrom appends the legal moves of the piece on sq to moves.
+func (g *Game) legalMovesFrom(from int, moves []move) []move {
+	if g.variant == nil {
+		return g.standardMovesFrom(from, moves)
+	}
+	for _, mv := range g.legalMoves(g.colorAt(from), nil) {
+		if mv.from == from {
+			moves = append(moves, mv)
+		}
+	}
+	return moves
+}
+
+// standardMoves appends all of c's legal moves in standard chess to moves.
+func (g *Game) standardMoves(c Color, moves []move) []move {
+	if !g.bitboards() {
+		for _, sq := range g.squaresOf(c) {
+			moves = g.standardMovesFrom(sq, moves)
+		}
+		return moves
+	}
+
+	own := g.colors[colorIndex(c)]
+	for own != 0 {
+		moves = g.standardMovesFrom(own.pop(), moves)
+	}
+	return moves
+}
+
+// standardMovesFrom appends the legal moves in standard
+// chess of the piece on sq to moves.
+func (g *Game) standardMovesFrom(from int, moves []move) []move {
+	c := g.colorAt(from)
+	if g.mailbox[from] == PieceKing {
+		moves = g.castleMoves(c, from, moves)
+	}
+
+	// only keep the moves which do not leave the player in check
+	start := len(moves)
+	moves = g.pseudoMovesFrom(from, promotions[:], moves)
+	legal := moves[:start]
+	for _, mv := range moves[start:] {
+		u := g.makeMove(mv)
+		inCheck := g.inCheck(c)
+		g.unmakeMove(u)
+		if !inCheck {
+			legal = append(legal, mv)
+		}
+	}
+
+	return legal
+}
+
+// pseudoMovesFrom appends the moves of the piece on sq to moves, without
+// considering if they leave the player in check. Pawns reaching the last
+// rank have a move for each of the given promotions. Castles are not
+// included, since in Chess960 the king may castle to a space that it
+// could also move to normally.
+func (g *Game) pseudoMovesFrom(from int, promotions []PieceType, moves []move) []move {
+	t := g.mailbox[from]
+	c := g.colorAt(from)
+	if !g.bitboards() {
+		return g.mailboxMoves(t, c, from, promotions, moves)
+	}
+
+	targets := g.pseudoTargets(t, c, from)
+	if t == PieceKing {
+		targets = g.attacks(t, c, from) &^ (g.colors[colorIndex(c)] | g.Duck)
+	}
+	for targets != 0 {
+		to := targets.pop()
+		mv := move{from: from, to: to}
+		if t != PiecePawn {
+			moves = append(moves, mv)
+			continue
+		}
+
+		mv.enPassant = g.hasEnPassant() && to == squareOf(g.EnPassant)
+		if to/8 == 0 || to/8 == 7 {
+			for _, promotion := range promotions {
+				mv.promotion = promotion
+				moves = append(moves, mv)
+			}
+			continue
+		}
+		moves = append(moves, mv)
+	}
+
+	return moves
+}
+
+// undo holds everything needed to take back a move.
+type undo struct {
+	move     move
+	captured PieceType
+
+	// the position before the move, only
+	// recorded for moves in the game's history
+	position PositionKey
+
+	// the pieces before the move, only recorded
+	// when a variant may change the board after it
+	pieces *pieces
+
+	castles    castlingRights
+	enPassant  Space
+	halfmove   int
+	completion CompletionState
+	checks     checkCounts
+}
+
+// pieces holds the pieces on the board and in the pockets.
+type pieces struct {
+	colors   [2]Bitboard
+	types    [pieceTypes]Bitboard
+	mailbox  [maxSquares]PieceType
+	whites   squareSet
+	hash     uint64
+	pockets  pockets
+	promoted Bitboard
+	duck     Bitboard
+}
+
+// makeMove makes mv without checking if it is legal, and returns
+// what is needed to take the move back with unmakeMove.
+func (g *Game) makeMove(mv move) undo {
+	moving := g.mailbox[mv.from]
+	c := g.colorAt(mv.from)
+
+	u := undo{
+		move:       mv,
+		castles:    g.Castles,
+		enPassant:  g.EnPassant,
+		halfmove:   g.Halfmove,
+		completion: g.Completion,
+		checks:     g.Checks,
+	}
+
+	var m Move
+	if g.variant != nil {
+		u.pieces = &pieces{
+			colors:   g.colors,
+			types:    g.types,
+			mailbox:  g.mailbox,
+			whites:   g.whites,
+			hash:     g.hash,
+			pockets:  g.Pockets,
+			promoted: g.Promoted,
+			duck:     g.Duck,
+		}
+		m = g.moveOf(mv)
+	}
+
+	var capturing bool
+	switch {
+	case mv.drop != PieceNone:
+		moving = mv.drop
+		g.put(mv.to, mv.drop, mv.dropColor)
+		g.Pockets.Of(mv.dropColor)[mv.drop]--
+	case mv.castle:
+		// the king and rook are both lifted first, since in
+		// Chess960 they may land on each other's spaces
+		rookFrom, rookTo := g.castleRook(c, mv.to)
+		g.remove(mv.from)
+		g.remove(rookFrom)
+		g.put(mv.to, PieceKing, c)
+		g.put(rookTo, PieceRook, c)
+	default:
+		u.captured = g.mailbox[mv.to]
+		capturing = u.captured != PieceNone
+		if capturing {
+			g.remove(mv.to)
+		}
+		if mv.enPassant {
+			u.captured = g.mailbox[g.enPassantVictim(mv)]
+			g.remove(g.enPassantVictim(mv))
+			capturing = true
+		}
+
+		g.remove(mv.from)
+		if mv.promotion != PieceNone {
+			g.put(mv.to, mv.promotion, c)
+		} else {
+			g.put(mv.to, moving, c)
+		}
+	}
+
+	// the duck moves after the rest of the move
+	if mv.duck != 0 {
+		g.Duck = mv.duck
+	}
+
+	// update castling rights, both for pieces moving
+	// away from and rooks being captured on their spaces
+	g.updateCastles(mv.from)
+	g.updateCastles(mv.to)
+
+	// update en passant target
+	g.EnPassant = Space{}
+	if files, _ := g.Size(); moving == PiecePawn && (mv.to-mv.from == 2*files || mv.from-mv.to == 2*files) {
+		g.EnPassant = g.space((mv.from + mv.to) / 2)
+	}
+
+	// update move counts
+	g.Fullmove++
+	if capturing || moving == PiecePawn {
+		g.Halfmove = 0
+	} else {
+		g.Halfmove++
+	}
+
+	if g.variant != nil {
+		g.variant.AfterMove(g, m, u.captured)
+	}
+
+	return u
+}
+
+// unmakeMove takes back the move that returned u from makeMove.
+// Moves must be taken back in the opposite order they were made.
+func (g *Game) unmakeMove(u undo) {
+	mv := u.move
+	c := g.colorAt(mv.to)
+
+	switch {
+	case u.pieces != nil:
+		g.colors = u.pieces.colors
+		g.types = u.pieces.types
+		g.mailbox = u.pieces.mailbox
+		g.whites = u.pieces.whites
+		g.hash = u.pieces.hash
+		g.Pockets = u.pieces.pockets
+		g.Promoted = u.pieces.promoted
+		g.Duck = u.pieces.duck
+	case mv.drop != PieceNone:
+		g.remove(mv.to)
+		g.Pockets.Of(mv.dropColor)[mv.drop]++
+	case mv.castle:
+		rookFrom, rookTo := g.castleRook(c, mv.to)
+		g.remove(mv.to)
+		g.remove(rookTo)
+		g.put(mv.from, PieceKing, c)
+		g.put(rookFrom, PieceRook, c)
+	default:
+		g.unmakePieces(u, c)
+	}
+
+	g.Castles = u.castles
+	g.EnPassant = u.enPassant
+	g.Halfmove = u.halfmove
+	g.Completion = u.completion
+	g.Checks = u.checks
+	g.Fullmove--
+}
+
+// unmakePieces puts back the pieces moved and captured by
+// u's move, which was made by c and is not a castle.
+func (g *Game) unmakePieces(u undo, c Color) {
+	mv := u.move
+	moving := g.mailbox[mv.to]
+	if mv.promotion != PieceNone {
+		moving = PiecePawn
+	}
+	g.remove(mv.to)
+	g.put(mv.from, moving, c)
+
+	if u.captured != PieceNone {
+		if mv.enPassant {
+			g.put(g.enPassantVictim(mv), u.captured, c.Other())
+		} else {
+			g.put(mv.to, u.captured, c.Other())
+		}
+	}
+}
+
+// enPassantVictim returns the square of the pawn captured by mv.
+func (g *Game) enPassantVictim(mv move) int {
+	files, _ := g.Size()
+	return mv.from/files*files + mv.to%files
+}
+
+// internalMove returns the compact form of m.
+func (g *Game) internalMove(m Move) move {
+	if m.Drop {
+		sq := g.square(m.To)
+		return move{from: sq, to: sq, drop: m.Moving.Type, dropColor: m.Moving.Color}
+	}
+
+	mv := move{
+		from:      g.square(m.Moving.Location),
+		to:        g.square(m.To),
+		promotion: m.Promotion,
+	}
+	switch m.Moving.Type {
+	case PieceKing:
+		if to, ok := g.castleTo(m.Moving.Color, mv.from, mv.to); ok {
+			mv.to, mv.castle = to, true
+		} else {
+			mv.castle = m.Castle
+		}
+	case PiecePawn:
+		mv.enPassant = g.hasEnPassant() && m.To == g.EnPassant
+	}
+	if _, ok := g.variant.(Duck); ok && m.HasDuck {
+		mv.duck = squareBit(squareOf(m.Duck))
+	}
+	return mv
+}
+
+// fullMove returns the Move form of mv, with a Snapshot of g.
+func (g *Game) fullMove(mv move) Move {
+	m := g.moveOf(mv)
+	m.Snapshot = *g
+	return m
+}
+
+// moveOf returns the Move form of mv without a Snapshot, since
+// copying the game is too slow for moves made while searching.
+func (g *Game) moveOf(mv move) Move {
+	if mv.drop != PieceNone {
+		return Move{
+			Moving: Piece{Game: g, Type: mv.drop, Color: mv.dropColor},
+			To:     g.space(mv.to),
+			Drop:   true,
+		}
+	}
+	m := Move{
+		Moving:    g.pieceOn(mv.from),
+		To:        g.space(mv.to),
+		Promotion: mv.promotion,
+		Castle:    mv.castle,
+		EnPassant: mv.enPassant,
+	}
+	if mv.duck != 0 {
+		m.Duck, m.HasDuck = spaceOf(mv.duck.lowest()), true
+	}
+	return m
+}
@@ -8,8 +8,28 @@ type Move struct {
 	To     Space
 
 	Promotion PieceType
+
+	// Castle is true if the move is a castle. To is
+	// the space that the king is moving to.
+	Castle bool
+
+	// EnPassant is true if the move captures a pawn en passant.
+	EnPassant bool
+
+	// Drop is true if Moving is dropped onto To from its player's
+	// pocket, in variants such as Crazyhouse. Moving.Location is not used.
+	Drop bool
+
+	// Duck is the space that the duck is moved to after the rest of the
+	// move, in Duck Chess, if HasDuck is true. Every move in Duck Chess
+	// must move the duck, and neither is used in other variants.
+	Duck    Space
+	HasDuck bool
 }
 
 func (m Move) String() string {
-	return "Move{" + m.Moving.String() + " to " + m.To.String()
+	if m.Drop {
+		return "Move{" + m.Moving.Color.String() + " " + m.Moving.Type.String() + " dropped on " + m.To.String() + "}"
+	}
+	return "Move{" + m.Moving.String() + " to " + m.To.String() + "}"
 }
@@ -0,0 +1,69 @@
+package chess
+
+import "strings"
+
+// Perft walks the tree of legal moves `depth` plies deep and returns
+// the number of leaf nodes. Comparing the result against known
+// values is the standard way to verify a move generator.
+func (g *Game) Perft(depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	moves := g.legalMoves(g.Turn(), nil)
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var nodes uint64
+	for _, mv := range moves {
+		u := g.makeMove(mv)
+		nodes += g.Perft(depth - 1)
+		g.unmakeMove(u)
+	}
+	return nodes
+}
+
+// Divide returns the perft of each legal move at `depth`, keyed by
+// the move in coordinate notation (ie "e2e4", or "e7e8q" for
+// promotions, or "e2e4,e5" in Duck Chess). In Chess960, castles are
+// written as the king moving onto its rook, as UCI does. The values
+// add up to g.Perft(depth).
+func (g *Game) Divide(depth int) map[string]uint64 {
+	divided := make(map[string]uint64)
+	for _, mv := range g.legalMoves(g.Turn(), nil) {
+		u := g.makeMove(mv)
+		divided[g.coordinate(mv)] = g.Perft(depth - 1)
+		g.unmakeMove(u)
+	}
+	return divided
+}
+
+// coordinate returns mv in coordinate notation. Drops are written
+// with the piece that is dropped, ie "N@f3", and the space that the
+// duck moves to in Duck Chess follows a comma, ie "e2e4,e5". Castles
+// in Chess960 are written as the king moving onto its rook, since
+// the king may castle to a space it could also move to normally.
+func (g *Game) coordinate(mv move) string {
+	var builder strings.Builder
+	if mv.drop != PieceNone {
+		builder.WriteByte(mv.drop.ShortName())
+		builder.WriteByte('@')
+		builder.WriteString(g.space(mv.to).String())
+	} else {
+		to := mv.to
+		if mv.castle && g.Chess960() {
+			to, _ = g.castleRook(g.colorAt(mv.from), mv.to)
+		}
+		builder.WriteString(g.space(mv.from).String())
+		builder.WriteString(g.space(to).String())
+		if mv.promotion != PieceNone {
+			builder.WriteByte(mv.promotion.ShortName() - 'A' + 'a')
+		}
+	}
+	if mv.duck != 0 {
+		builder.WriteByte(',')
+		builder.WriteString(spaceOf(mv.duck.lowest()).String())
+	}
+	return builder.String()
+}
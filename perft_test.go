@@ -0,0 +1,124 @@
+package chess_test
+
+import (
+	"testing"
+
+	"github.com/deanveloper/chess/encoder"
+)
+
+// positions and node counts from https://www.chessprogramming.org/Perft_Results
+// and https://www.chessprogramming.org/Chess960_Perft_Results
+var perftTests = []struct {
+	name  string
+	fen   string
+	nodes []uint64
+}{
+	{
+		name:  "start position",
+		fen:   "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		nodes: []uint64{20, 400, 8902, 197281, 4865609},
+	},
+	{
+		name:  "kiwipete",
+		fen:   "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		nodes: []uint64{48, 2039, 97862, 4085603},
+	},
+	{
+		name:  "position 3",
+		fen:   "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		nodes: []uint64{14, 191, 2812, 43238, 674624},
+	},
+	{
+		name:  "position 4",
+		fen:   "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		nodes: []uint64{6, 264, 9467, 422333},
+	},
+	{
+		name:  "position 5",
+		fen:   "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+		nodes: []uint64{44, 1486, 62379, 2103487},
+	},
+	{
+		name:  "position 6",
+		fen:   "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+		nodes: []uint64{46, 2079, 89890, 3894594},
+	},
+	{
+		name:  "chess960 position 1",
+		fen:   "bqnb1rkr/pp3ppp/3ppn2/2p5/5P2/P2P4/NPP1P1PP/BQ1BNRKR w HFhf - 2 9",
+		nodes: []uint64{21, 528, 12189, 326672},
+	},
+	{
+		name:  "chess960 position 3",
+		fen:   "b1q1rrkb/pppppppp/3nn3/8/P7/1PPP4/4PPPP/BQNNRKRB w GE - 1 9",
+		nodes: []uint64{20, 479, 10471, 273318},
+	},
+	{
+		// the king may castle or move to g1, which
+		// must be different moves in Divide
+		name:  "chess960 castle onto a king move",
+		fen:   "4k3/8/8/8/8/8/8/5K1R w H - 0 1",
+		nodes: []uint64{14, 63, 1128, 6659},
+	},
+}
+
+// the most nodes that a single perft will search, so
+// that the suite finishes in a reasonable amount of time.
+const (
+	perftShortLimit = 10000
+	perftLimit      = 5000000
+)
+
+func TestPerft(t *testing.T) {
+	for _, test := range perftTests {
+		game, err := encoder.FromFEN(test.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		for i, want := range test.nodes {
+			depth := i + 1
+			if want > perftLimit || testing.Short() && want > perftShortLimit {
+				break
+			}
+
+			got := game.Perft(depth)
+			if got != want {
+				t.Errorf("%s: perft(%d) = %d, want %d\ndivide: %v", test.name, depth, got, want, game.Divide(depth))
+				break
+			}
+		}
+	}
+}
+
+func TestDivide(t *testing.T) {
+	for _, test := range perftTests {
+		game, err := encoder.FromFEN(test.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		if moves := len(game.Divide(1)); uint64(moves) != test.nodes[0] {
+			t.Errorf("%s: divide(1) has %d moves, want %d", test.name, moves, test.nodes[0])
+		}
+
+		var sum uint64
+		for _, nodes := range game.Divide(2) {
+			sum += nodes
+		}
+		if sum != test.nodes[1] {
+			t.Errorf("%s: divide(2) adds up to %d, want %d", test.name, sum, test.nodes[1])
+		}
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	game, err := encoder.FromFEN(perftTests[1].fen)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		game.Perft(3)
+	}
+}
@@ -39,15 +39,27 @@ type PieceType byte
 
 // Symbol returns a rune representing the piece
 func (p PieceType) Symbol() rune {
+	if p >= firstFairyPiece {
+		if def := p.fairy().def; def.Symbol != 0 {
+			return def.Symbol
+		}
+		return rune(p.ShortName())
+	}
 	return [...]rune{' ', '♟', '♜', '♞', '♝', '♛', '♚'}[p]
 }
 
 // ShortName returns the shortname for p used by Forsyth-Edwards Notation.
 func (p PieceType) ShortName() byte {
+	if p >= firstFairyPiece {
+		return p.fairy().def.ShortName
+	}
 	return [...]byte{'X', 'P', 'R', 'N', 'B', 'Q', 'K'}[p]
 }
 
 func (p PieceType) String() string {
+	if p >= firstFairyPiece {
+		return p.fairy().def.Name
+	}
 	return [...]string{"None", "Pawn", "Rook", "Knight", "Bishop", "Queen", "King"}[p]
 }
 
@@ -67,274 +79,44 @@ func (p Piece) String() string {
 // the piece can see a square does not mean the move is valid; as the
 // player may be in check, or moving the piece may put the player in check.
 func (p Piece) Seeing() []Space {
-	var moveTo []Space
-
-	cur := p.Location
-
-	switch p.Type {
-	case PiecePawn:
-		// allow moving one up if there is not a piece there
-		next := p.Location
-		if p.Color == Black {
-			next.Rank--
-		} else {
-			next.Rank++
-		}
-		if _, ok := p.Game.PieceAt(next); !ok {
-			moveTo = append(moveTo, next)
-		}
-
-		// if unmoved, allow moving two up
-		if (p.Color == White && cur.Rank == 1) || (p.Color == Black && cur.Rank == 6) {
-			twoUp := next
-			if p.Color == Black {
-				twoUp.Rank--
-			} else {
-				twoUp.Rank++
-			}
-			if _, ok := p.Game.PieceAt(next); !ok {
-				if _, ok2 := p.Game.PieceAt(twoUp); !ok2 {
-					moveTo = append(moveTo, twoUp)
-				}
-			}
-		}
-
-		// allow diagonals if it can take
-		diagL := Space{Rank: next.Rank, File: next.File - 1}
-		diagR := Space{Rank: next.Rank, File: next.File + 1}
-		if _, ok := p.Game.PieceAt(diagL); ok {
-			moveTo = append(moveTo, diagL)
-		}
-		if _, ok := p.Game.PieceAt(diagR); ok {
-			moveTo = append(moveTo, diagR)
-		}
-
-		// include possibility of en passant
-		if diagL == p.Game.EnPassant || diagR == p.Game.EnPassant {
-			moveTo = append(moveTo, p.Game.EnPassant)
-		}
-
-	case PieceRook:
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File + 1, Rank: s.Rank}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File - 1, Rank: s.Rank}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File, Rank: s.Rank - 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File, Rank: s.Rank + 1}
-		})...)
-	case PieceKnight:
-		moveTo = []Space{
-			Space{File: cur.File + 1, Rank: cur.Rank + 2},
-			Space{File: cur.File + 2, Rank: cur.Rank + 1},
-			Space{File: cur.File + 1, Rank: cur.Rank - 2},
-			Space{File: cur.File + 2, Rank: cur.Rank - 1},
-			Space{File: cur.File - 1, Rank: cur.Rank + 2},
-			Space{File: cur.File - 2, Rank: cur.Rank + 1},
-			Space{File: cur.File - 1, Rank: cur.Rank - 2},
-			Space{File: cur.File - 2, Rank: cur.Rank - 1},
-		}
-	case PieceBishop:
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File + 1, Rank: s.Rank + 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File + 1, Rank: s.Rank - 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File - 1, Rank: s.Rank + 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File - 1, Rank: s.Rank - 1}
-		})...)
-	case PieceQueen:
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File + 1, Rank: s.Rank}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File - 1, Rank: s.Rank}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File, Rank: s.Rank - 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File, Rank: s.Rank + 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File + 1, Rank: s.Rank + 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File + 1, Rank: s.Rank - 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File - 1, Rank: s.Rank + 1}
-		})...)
-		moveTo = append(moveTo, p.loop(func(s Space) Space {
-			return Space{File: s.File - 1, Rank: s.Rank - 1}
-		})...)
-	case PieceKing:
-		// invalid spaces and spaces with own color's pieces
-		// are removed later
-		moveTo = []Space{
-			Space{File: cur.File + 1, Rank: cur.Rank + 1},
-			Space{File: cur.File + 1, Rank: cur.Rank - 1},
-			Space{File: cur.File - 1, Rank: cur.Rank + 1},
-			Space{File: cur.File - 1, Rank: cur.Rank - 1},
-			Space{File: cur.File + 1, Rank: cur.Rank},
-			Space{File: cur.File - 1, Rank: cur.Rank},
-			Space{File: cur.File, Rank: cur.Rank + 1},
-			Space{File: cur.File, Rank: cur.Rank - 1},
-		}
-
-		// castling:
-		if p.Color == White {
-			if p.Game.Castles.WhiteQueen {
-				moveTo = append(moveTo, Space{File: 2, Rank: cur.Rank})
-			}
-			if p.Game.Castles.WhiteKing {
-				moveTo = append(moveTo, Space{File: 6, Rank: cur.Rank})
-			}
-		} else {
-			if p.Game.Castles.BlackQueen {
-				moveTo = append(moveTo, Space{File: 2, Rank: cur.Rank})
-			}
-			if p.Game.Castles.BlackKing {
-				moveTo = append(moveTo, Space{File: 6, Rank: cur.Rank})
-			}
-		}
+	if !p.Game.OnBoard(p.Location) {
+		return nil
 	}
-
-	// remove anything where a piece is being taken of the same color,
-	// or anything off of the board.
-	sees := make([]Space, 0, len(moveTo))
-	for i := 0; i < len(moveTo); i++ {
-		s := moveTo[i]
-		if !s.Valid() {
-			continue
-		}
-		if other, ok := p.Game.PieceAt(s); ok && other.Color == p.Color {
-			continue
-		}
-		sees = append(sees, s)
+	if !p.Game.bitboards() {
+		return p.Game.mailboxSeeing(p)
 	}
 
-	return sees
+	return p.Game.Variant().Seeing(p.Game, p).Spaces()
 }
 
 // LegalMoves returns all of the legal moves for p.
 func (p Piece) LegalMoves() []Space {
-	var legal []Space
-
-	for _, space := range p.Seeing() {
-
-		// special case - remove castles in their cases
-		if p.Type == PieceKing {
-			diff := p.Location.File - space.File
-
-			// remove ability if pieces are between the rook and king
-			if diff == -2 {
-				if p := p.Game.board[1][p.Location.Rank]; p.Type != PieceNone {
-					continue
-				}
-				if p := p.Game.board[2][p.Location.Rank]; p.Type != PieceNone {
-					continue
-				}
-				if p := p.Game.board[3][p.Location.Rank]; p.Type != PieceNone {
-					continue
-				}
-			}
-			if diff == 2 {
-				if p := p.Game.board[5][p.Location.Rank]; p.Type != PieceNone {
-					continue
-				}
-				if p := p.Game.board[6][p.Location.Rank]; p.Type != PieceNone {
-					continue
-				}
-			}
-
-			// no castling at all while in check
-			if diff == -2 || diff == 2 {
-				if p.Game.InCheck(p.Color) {
-					continue
-				}
-			}
-
-			// queen-side castle
-			if diff == -2 {
-				clone := p.Game.Clone()
-				clone.MakeMoveUnconditionally(Move{
-					Moving: p,
-					To:     Space{File: 3, Rank: p.Location.Rank},
-				})
-				if clone.InCheck(p.Color) {
-					continue
-				}
-
-				clone = p.Game.Clone()
-				clone.MakeMoveUnconditionally(Move{
-					Moving: p,
-					To:     Space{File: 2, Rank: p.Location.Rank},
-				})
-				if clone.InCheck(p.Color) {
-					continue
-				}
-			}
-			// king-side castle
-			if diff == 2 {
-				clone := p.Game.Clone()
-				clone.MakeMoveUnconditionally(Move{
-					Moving: p,
-					To:     Space{File: 5, Rank: p.Location.Rank},
-				})
-				if clone.InCheck(p.Color) {
-					continue
-				}
-
-				clone = p.Game.Clone()
-				clone.MakeMoveUnconditionally(Move{
-					Moving: p,
-					To:     Space{File: 6, Rank: p.Location.Rank},
-				})
-				if clone.InCheck(p.Color) {
-					continue
-				}
-			}
-		}
-
-		newG := p.Game.Clone()
-		newG.MakeMoveUnconditionally(Move{
-			Snapshot: *newG,
-			Moving:   p,
-			To:       space,
-		})
-		if newG.InCheck(p.Color) {
-			continue
-		}
-		legal = append(legal, space)
+	var targets squareSet
+	for _, mv := range p.legalMoves() {
+		targets.add(mv.to)
 	}
-	return legal
+	return p.Game.spacesIn(targets)
 }
 
-func (p Piece) loop(next func(Space) Space) []Space {
-	var spaces []Space
+// Moves returns all of the legal moves for p as Moves. Pawns
+// reaching the last rank have a separate move for each piece
+// that they are able to promote to.
+func (p Piece) Moves() []Move {
+	internal := p.legalMoves()
 
-	cur := p.Location
-	for {
-		cur = next(cur)
-		if !cur.Valid() {
-			break
-		}
-
-		spaces = append(spaces, cur)
+	moves := make([]Move, len(internal))
+	for i, mv := range internal {
+		moves[i] = p.Game.fullMove(mv)
+	}
+	return moves
+}
 
-		if _, ok := p.Game.PieceAt(cur); ok {
-			break
-		}
+// legalMoves returns the legal moves for p, or no
+// moves if p is not on the board.
+func (p Piece) legalMoves() []move {
+	if actual, ok := p.Game.PieceAt(p.Location); !ok || actual.Type != p.Type || actual.Color != p.Color {
+		return nil
 	}
 
-	return spaces
+	return p.Game.legalMovesFrom(p.Game.square(p.Location), nil)
 }
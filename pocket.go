@@ -0,0 +1,61 @@
+package chess
+
+// Pocket holds the number of each type of piece that a player
+// may drop onto the board, indexed by PieceType.
+type Pocket [pieceTypes]int
+
+// Count returns the number of pieces in p.
+func (p Pocket) Count() int {
+	var count int
+	for _, n := range p {
+		count += n
+	}
+	return count
+}
+
+type pockets struct {
+	White, Black Pocket
+}
+
+// Of returns c's pocket.
+func (p *pockets) Of(c Color) *Pocket {
+	if c == White {
+		return &p.White
+	}
+	return &p.Black
+}
+
+// dropMoves appends every legal drop of the pieces in c's pocket to moves.
+// Pawns may not be dropped onto the first or last rank.
+func (g *Game) dropMoves(c Color, moves []move) []move {
+	pocket := g.Pockets.Of(c)
+	inCheck := g.inCheck(c)
+
+	for t, n := range pocket {
+		if n == 0 {
+			continue
+		}
+
+		targets := ^g.occupied()
+		if PieceType(t) == PiecePawn {
+			targets &^= 0xFF000000000000FF
+		}
+		for targets != 0 {
+			sq := targets.pop()
+			mv := move{from: sq, to: sq, drop: PieceType(t), dropColor: c}
+
+			// a drop can only leave the player in check
+			// if they were already in check
+			if inCheck {
+				u := g.makeMove(mv)
+				blocked := !g.inCheck(c)
+				g.unmakeMove(u)
+				if !blocked {
+					continue
+				}
+			}
+			moves = append(moves, mv)
+		}
+	}
+	return moves
+}
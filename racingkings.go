@@ -0,0 +1,104 @@
+package chess
+
+// the eighth rank, which the kings race to in Racing Kings
+const eighthRank Bitboard = 0xFF00000000000000
+
+// RacingKings is the Variant where the first king to reach the eighth rank
+// wins, and no move may give check. Since white moves first, black gets
+// one more move to also reach the eighth rank after white's king does,
+// which draws the game.
+type RacingKings struct {
+	Standard
+}
+
+// Name returns "Racing Kings".
+func (RacingKings) Name() string {
+	return "Racing Kings"
+}
+
+// Init sets up both players' pieces side by side on the first two ranks.
+func (RacingKings) Init(g *Game) {
+	var board [8][8]Piece
+	first := [8]PieceType{PieceQueen, PieceRook, PieceBishop, PieceKnight, PieceKnight, PieceBishop, PieceRook, PieceQueen}
+	second := [8]PieceType{PieceKing, PieceRook, PieceBishop, PieceKnight, PieceKnight, PieceBishop, PieceRook, PieceKing}
+	for file := 0; file < 8; file++ {
+		c := Black
+		if file >= 4 {
+			c = White
+		}
+		board[file][0] = Piece{Type: first[file], Color: c}
+		board[file][1] = Piece{Type: second[file], Color: c}
+	}
+	g.InitCustom(board)
+}
+
+// Moves appends all of c's legal moves to moves, which are
+// the standard moves that do not put the other player in check.
+func (RacingKings) Moves(g *Game, c Color, moves []Move) []Move {
+	if raceResult(g).Done {
+		return moves
+	}
+
+	for _, mv := range g.standardMoves(c, nil) {
+		u := g.makeMove(mv)
+		check := g.inCheck(c.Other())
+		g.unmakeMove(u)
+		if !check {
+			moves = append(moves, g.moveOf(mv))
+		}
+	}
+	return moves
+}
+
+// Completion ends the game once a king has reached the eighth rank, or by
+// stalemate, the 75 move rule, or fivefold repetition. Since the kings can
+// always race, the game is not drawn by insufficient material.
+func (RacingKings) Completion(g *Game) CompletionState {
+	if result := raceResult(g); result.Done {
+		return result
+	}
+	if g.InStalemate(g.Turn()) {
+		return CompletionState{Done: true, Draw: true, Reason: TerminationStalemate}
+	}
+	return g.automaticDraw()
+}
+
+// raceResult returns the completion state of g by the kings reaching the
+// eighth rank, which is not done if neither has, or if white's king has
+// and black is still able to reply by also reaching it.
+func raceResult(g *Game) CompletionState {
+	white := g.Bitboard(White, PieceKing)&eighthRank != 0
+	black := g.Bitboard(Black, PieceKing)&eighthRank != 0
+
+	switch {
+	case white && black:
+		return CompletionState{Done: true, Draw: true, Reason: TerminationEighthRank}
+	case black:
+		return CompletionState{Done: true, Winner: Black, Reason: TerminationEighthRank}
+	case white && (g.Turn() == White || !canReachEighthRank(g, Black)):
+		return CompletionState{Done: true, Winner: White, Reason: TerminationEighthRank}
+	}
+	return CompletionState{}
+}
+
+// canReachEighthRank returns if c's king has a legal
+// move in Racing Kings which reaches the eighth rank.
+func canReachEighthRank(g *Game, c Color) bool {
+	king := g.Bitboard(c, PieceKing)
+	if king == 0 {
+		return false
+	}
+
+	for _, mv := range g.standardMovesFrom(king.lowest(), nil) {
+		if squareBit(mv.to)&eighthRank == 0 {
+			continue
+		}
+		u := g.makeMove(mv)
+		check := g.inCheck(c.Other())
+		g.unmakeMove(u)
+		if !check {
+			return true
+		}
+	}
+	return false
+}
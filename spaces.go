@@ -9,9 +9,16 @@ type Space struct {
 	File, Rank int
 }
 
-// Valid returns if the space is a valid space to be occupying
+// Valid returns if the space is on the largest board that a game may be
+// played on, which is 16x16. Use Game.OnBoard to check if the space is on
+// the board of a particular game.
 func (s Space) Valid() bool {
-	return s.Rank >= 0 && s.Rank < 8 && s.File >= 0 && s.File < 8
+	return s.within(maxFiles, maxRanks)
+}
+
+// within returns if s is on a board with the given number of files and ranks.
+func (s Space) within(files, ranks int) bool {
+	return s.Rank >= 0 && s.Rank < ranks && s.File >= 0 && s.File < files
 }
 
 // Color returns the color of this space
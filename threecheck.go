@@ -0,0 +1,97 @@
+package chess
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+type checkCounts struct {
+	White, Black int
+}
+
+// Of returns the number of checks that c has given.
+func (n checkCounts) Of(c Color) int {
+	if c == White {
+		return n.White
+	}
+	return n.Black
+}
+
+// ThreeCheck is the Variant where a player also wins by giving check
+// three times. The checks given are counted in Game.Checks, and are
+// written in FEN as a seventh field "+N+M", where N is the number of
+// checks given by White and M is the number given by Black.
+type ThreeCheck struct {
+	Standard
+}
+
+// Name returns "Three-check".
+func (ThreeCheck) Name() string {
+	return "Three-check"
+}
+
+// AfterMove counts the check given by m, if any.
+func (ThreeCheck) AfterMove(g *Game, m Move, captured PieceType) {
+	if !g.inCheck(g.Turn()) {
+		return
+	}
+	if m.Moving.Color == White {
+		g.Checks.White++
+	} else {
+		g.Checks.Black++
+	}
+}
+
+// Completion ends the game once a player has given three checks, or by
+// the standard rules. Since any piece can give check, the game is only
+// drawn by insufficient material if both players only have a king.
+func (ThreeCheck) Completion(g *Game) CompletionState {
+	mover := g.Turn().Other()
+	switch {
+	case g.Checks.Of(mover) >= 3:
+		return CompletionState{Done: true, Winner: mover, Reason: TerminationThreeCheck}
+	case g.InCheckmate(g.Turn()):
+		return CompletionState{Done: true, Winner: mover, Reason: TerminationCheckmate}
+	case g.InStalemate(g.Turn()):
+		return CompletionState{Done: true, Draw: true, Reason: TerminationStalemate}
+	case g.InsufficientMaterial() == MaterialKingVsKing:
+		return CompletionState{Done: true, Draw: true, Reason: TerminationInsufficientMaterial}
+	}
+	return g.automaticDraw()
+}
+
+// ReadFEN reads the number of checks given from the seventh field, if
+// there is one.
+func (ThreeCheck) ReadFEN(g *Game, fields []string) error {
+	switch len(fields) {
+	case 6:
+		return Standard{}.ReadFEN(g, fields)
+	case 7:
+		if err := (Standard{}).ReadFEN(g, fields[:6]); err != nil {
+			return err
+		}
+	default:
+		return errors.New("expected 6 or 7 fields")
+	}
+
+	counts := strings.Split(fields[6], "+")
+	if len(counts) != 3 || counts[0] != "" {
+		return errors.New("invalid checks given " + fields[6])
+	}
+	white, err := strconv.Atoi(counts[1])
+	if err != nil || white < 0 {
+		return errors.New("invalid checks given " + fields[6])
+	}
+	black, err := strconv.Atoi(counts[2])
+	if err != nil || black < 0 {
+		return errors.New("invalid checks given " + fields[6])
+	}
+	g.Checks = checkCounts{White: white, Black: black}
+	return nil
+}
+
+// WriteFEN adds the number of checks given as a seventh field.
+func (ThreeCheck) WriteFEN(g *Game, fields []string) []string {
+	return append(fields, "+"+strconv.Itoa(g.Checks.White)+"+"+strconv.Itoa(g.Checks.Black))
+}
@@ -0,0 +1,205 @@
+package chess
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Variant is a set of rules that a Game is played by. Each method is
+// given the game that it is deciding the rules for. Variants usually
+// embed Standard, and only override the rules that they change. Variants
+// change the board with Game.Put and Game.Remove, so they may also be
+// written outside of this package.
+type Variant interface {
+	// Name returns the name of the variant, as used by the PGN Variant tag.
+	Name() string
+
+	// Init sets up the start position of the variant on g, which
+	// has already been reset and uses the variant.
+	Init(g *Game)
+
+	// Seeing returns the spaces that p can see. Just because p
+	// can see a space does not mean that moving there is legal.
+	// It is only used for 8x8 boards.
+	Seeing(g *Game, p Piece) Bitboard
+
+	// Moves appends all of c's legal moves to moves. The
+	// moves do not need to have a Snapshot.
+	Moves(g *Game, c Color, moves []Move) []Move
+
+	// AfterMove is called after m has been made as it would be in
+	// standard chess, so that the variant may change the game further.
+	// m does not have a Snapshot, and captured is the type of the piece
+	// that m captured, or PieceNone. Changes to the board and to the
+	// fields restored by UnmakeMove are taken back along with the move.
+	AfterMove(g *Game, m Move, captured PieceType)
+
+	// InCheck returns if c is in check.
+	InCheck(g *Game, c Color) bool
+
+	// Completion returns the completion state of g after
+	// a move, which is not done if the game continues.
+	Completion(g *Game) CompletionState
+
+	// ReadFEN reads any parts of a FEN that the variant adds to standard
+	// FEN into g, which has already been set up from the standard fields.
+	// It returns an error if the FEN has any parts that it does not expect.
+	ReadFEN(g *Game, fields []string) error
+
+	// WriteFEN returns the fields of g's FEN, given the fields
+	// that would be written for it in standard FEN.
+	WriteFEN(g *Game, fields []string) []string
+}
+
+// Standard is the Variant for standard chess, which
+// is the variant that games use unless they are set to
+// another. Embed it to inherit the standard rules.
+type Standard struct{}
+
+// Name returns "Standard".
+func (Standard) Name() string {
+	return "Standard"
+}
+
+// Init sets up the classic chess layout.
+func (Standard) Init(g *Game) {
+	g.InitClassic()
+}
+
+// Seeing returns the spaces that p can see in standard chess. Boards that
+// are not 8x8 do not fit in a Bitboard, so it returns no spaces for them,
+// and Piece.Seeing finds the spaces without the variant.
+func (Standard) Seeing(g *Game, p Piece) Bitboard {
+	if !g.bitboards() {
+		return 0
+	}
+	return g.pseudoTargets(p.Type, p.Color, squareOf(p.Location))
+}
+
+// Moves appends all of c's legal moves in standard chess to moves.
+func (Standard) Moves(g *Game, c Color, moves []Move) []Move {
+	for _, mv := range g.standardMoves(c, nil) {
+		moves = append(moves, g.moveOf(mv))
+	}
+	return moves
+}
+
+// AfterMove does nothing, as standard chess only moves the pieces of m.
+func (Standard) AfterMove(g *Game, m Move, captured PieceType) {}
+
+// InCheck returns if c's king is attacked. Players without a king are never in check.
+func (Standard) InCheck(g *Game, c Color) bool {
+	return g.inCheck(c)
+}
+
+// Completion ends the game by checkmate, stalemate, insufficient
+// material, the 75 move rule, or fivefold repetition.
+func (Standard) Completion(g *Game) CompletionState {
+	switch {
+	case g.InCheckmate(g.Turn()):
+		return CompletionState{Done: true, Winner: g.Turn().Other(), Reason: TerminationCheckmate}
+	case g.InStalemate(g.Turn()):
+		return CompletionState{Done: true, Draw: true, Reason: TerminationStalemate}
+	case g.InsufficientMaterial() != MaterialSufficient:
+		return CompletionState{Done: true, Draw: true, Reason: TerminationInsufficientMaterial}
+	}
+	return g.automaticDraw()
+}
+
+// automaticDraw returns a drawn completion state if the game
+// is drawn by the 75 move rule or fivefold repetition, which
+// end the game without needing to be claimed.
+func (g *Game) automaticDraw() CompletionState {
+	switch {
+	case g.Halfmove >= 150:
+		return CompletionState{Done: true, Draw: true, Reason: TerminationSeventyFiveMoveRule}
+	case g.RepetitionCount() >= 5:
+		return CompletionState{Done: true, Draw: true, Reason: TerminationFivefoldRepetition}
+	}
+	return CompletionState{}
+}
+
+// ReadFEN returns an error if the FEN has anything more than
+// the six standard fields, or has pockets or promoted pieces.
+func (Standard) ReadFEN(g *Game, fields []string) error {
+	if len(fields) != 6 {
+		return errors.New("expected 6 fields")
+	}
+	if strings.ContainsRune(fields[0], '[') {
+		return errors.New("unexpected pocket in board state")
+	}
+	if strings.ContainsRune(fields[0], '~') {
+		return errors.New("unexpected promoted piece in board state")
+	}
+	if strings.ContainsRune(fields[0], '*') {
+		return errors.New("unexpected duck in board state")
+	}
+	return nil
+}
+
+// WriteFEN returns the standard fields.
+func (Standard) WriteFEN(g *Game, fields []string) []string {
+	return fields
+}
+
+// Variant returns the variant that g is played by.
+func (g *Game) Variant() Variant {
+	if g.variant == nil {
+		return Standard{}
+	}
+	return g.variant
+}
+
+// SetVariant changes the variant that g is played
+// by, without changing the position on the board.
+func (g *Game) SetVariant(v Variant) {
+	// standard chess is stored as nil, which lets
+	// the move generator skip calling the variant
+	if _, ok := v.(Standard); ok {
+		v = nil
+	}
+	g.variant = v
+
+	// variants with their own fairy pieces register them when they
+	// are first used, so that they can be read from FEN
+	if r, ok := v.(pieceRegisterer); ok {
+		r.registerPieces()
+	}
+}
+
+// pieceRegisterer is implemented by variants which register
+// their own fairy pieces, such as Capablanca.
+type pieceRegisterer interface {
+	registerPieces()
+}
+
+// InitVariant initializes g to the start position of v, and plays the game by v's rules.
+func (g *Game) InitVariant(v Variant) {
+	*g = Game{}
+	g.SetVariant(v)
+	g.Variant().Init(g)
+}
+
+// Put puts p on p.Location, replacing any piece already there. It is meant
+// for variants, such as in Init or AfterMove, and does not check that the
+// position is legal. It panics if p.Location is not on the board.
+func (g *Game) Put(p Piece) {
+	if !g.OnBoard(p.Location) {
+		panic(fmt.Sprintf("space is not on the board: %+v", p.Location))
+	}
+	sq := g.square(p.Location)
+	g.remove(sq)
+	if p.Type != PieceNone {
+		g.put(sq, p.Type, p.Color)
+	}
+}
+
+// Remove removes the piece on s, if any. Like Put, it is meant for
+// variants. It panics if s is not on the board.
+func (g *Game) Remove(s Space) {
+	if !g.OnBoard(s) {
+		panic(fmt.Sprintf("space is not on the board: %+v", s))
+	}
+	g.remove(g.square(s))
+}
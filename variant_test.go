@@ -0,0 +1,628 @@
+package chess_test
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/deanveloper/chess"
+	"github.com/deanveloper/chess/encoder"
+)
+
+// wrapped plays standard chess through the Variant interface,
+// rather than the move generator's fast path for standard chess.
+type wrapped struct {
+	chess.Standard
+}
+
+func (wrapped) Name() string {
+	return "Wrapped"
+}
+
+// kamikaze is a variant written outside of the package, where
+// capturing pieces are removed along with the piece they capture.
+type kamikaze struct {
+	chess.Standard
+}
+
+func (kamikaze) Name() string {
+	return "Kamikaze"
+}
+
+func (kamikaze) Init(g *chess.Game) {
+	g.InitClassic()
+	g.Put(chess.Piece{Type: chess.PieceKnight, Color: chess.White, Location: chess.Space{File: 3, Rank: 4}})
+}
+
+func (kamikaze) AfterMove(g *chess.Game, m chess.Move, captured chess.PieceType) {
+	if captured != chess.PieceNone {
+		g.Remove(m.To)
+	}
+}
+
+func TestExternalVariant(t *testing.T) {
+	game := &chess.Game{}
+	game.InitVariant(kamikaze{})
+	if got, want := readAll(t, encoder.FENReader(game)), "rnbqkbnr/pppppppp/8/3N4/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"; got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+
+	move, err := encoder.FromAlgebraic(game, "Nxc7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readAll(t, encoder.FENReader(game)), "rnbqkbnr/pp1ppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1"; got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+
+	if err := game.UnmakeMove(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readAll(t, encoder.FENReader(game)), "rnbqkbnr/pppppppp/8/3N4/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"; got != want {
+		t.Errorf("FEN after UnmakeMove = %q, want %q", got, want)
+	}
+}
+
+func TestVariantPerft(t *testing.T) {
+	for _, test := range perftTests {
+		game, err := encoder.FromVariantFEN(wrapped{}, test.fen)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		for i, want := range test.nodes {
+			depth := i + 1
+			if want > perftShortLimit {
+				break
+			}
+
+			got := game.Perft(depth)
+			if got != want {
+				t.Errorf("%s: perft(%d) = %d, want %d", test.name, depth, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestKingOfTheHill(t *testing.T) {
+	game, err := encoder.FromVariantFEN(chess.KingOfTheHill{}, "4k3/8/8/8/8/4K3/8/8 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	king, _ := game.PieceAt(chess.Space{File: 4, Rank: 2})
+	err = game.MakeMove(chess.Move{Moving: king, To: chess.Space{File: 3, Rank: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := chess.CompletionState{Done: true, Winner: chess.White, Reason: chess.TerminationKingOfTheHill}
+	if game.Completion != want {
+		t.Errorf("completion = %v, want %v", game.Completion, want)
+	}
+	if moves := game.LegalMoves(); len(moves) != 0 {
+		t.Errorf("legal moves after the game ended = %v", moves)
+	}
+}
+
+func TestThreeCheck(t *testing.T) {
+	fen := "rnbqkbnr/ppp2ppp/8/3pp3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 3 +2+0"
+	game, err := encoder.FromVariantFEN(chess.ThreeCheck{}, fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, encoder.FENReader(game)); got != fen {
+		t.Errorf("FEN = %q, want %q", got, fen)
+	}
+
+	move, err := encoder.FromAlgebraic(game, "Bb5+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+
+	want := chess.CompletionState{Done: true, Winner: chess.White, Reason: chess.TerminationThreeCheck}
+	if game.Completion != want {
+		t.Errorf("completion = %v, want %v", game.Completion, want)
+	}
+	if game.Checks.White != 3 {
+		t.Errorf("white checks = %d, want 3", game.Checks.White)
+	}
+
+	if err := game.UnmakeMove(); err != nil {
+		t.Fatal(err)
+	}
+	if game.Checks.White != 2 || game.Completion.Done {
+		t.Errorf("after undo: white checks = %d, completion = %v", game.Checks.White, game.Completion)
+	}
+}
+
+func readAll(t *testing.T, r io.Reader) string {
+	t.Helper()
+	all, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(all)
+}
+
+func TestAtomic(t *testing.T) {
+	// the start position diverges from standard chess at depth 4
+	game, err := encoder.FromVariantFEN(chess.Atomic{}, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []uint64{20, 400, 8902, 197326} {
+		if got := game.Perft(i + 1); got != want {
+			t.Errorf("perft(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// Nxe7 explodes the queen, bishop and king next to it,
+	// but leaves the pawns
+	game, err = encoder.FromVariantFEN(chess.Atomic{}, "rnbqkbnr/ppppp1pp/5p2/3N4/8/8/PPPPPPPP/R1BQKBNR w KQkq - 0 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err := encoder.FromAlgebraic(game, "Nxe7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "rnb3nr/pppp2pp/5p2/8/8/8/PPPPPPPP/R1BQKBNR b KQ - 0 3"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+	wantCompletion := chess.CompletionState{Done: true, Winner: chess.White, Reason: chess.TerminationExplosion}
+	if game.Completion != wantCompletion {
+		t.Errorf("completion = %v, want %v", game.Completion, wantCompletion)
+	}
+}
+
+func TestCrazyhouse(t *testing.T) {
+	// drops are not possible until white's third move
+	game, err := encoder.FromVariantFEN(chess.Crazyhouse{}, "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR[] w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []uint64{20, 400, 8902, 197281} {
+		if got := game.Perft(i + 1); got != want {
+			t.Errorf("perft(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// the promoted queen goes to white's pocket as a pawn
+	fen := "4k3/8/8/8/8/8/4q~3/4K3[Np] w - - 0 1"
+	game, err = encoder.FromVariantFEN(chess.Crazyhouse{}, fen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readAll(t, encoder.FENReader(game)); got != fen {
+		t.Errorf("FEN = %q, want %q", got, fen)
+	}
+	for _, alg := range []string{"Kxe2", "@e3"} {
+		move, err := encoder.FromAlgebraic(game, alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := game.MakeMove(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "4k3/8/8/8/8/4p3/4K3/8[NP] w - - 0 2"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+	if game.Completion.Done {
+		t.Errorf("completion = %v, want the game to continue", game.Completion)
+	}
+
+	move, err := encoder.FromAlgebraic(game, "N@d4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := encoder.Algebraic(move); got != "N@d4" {
+		t.Errorf("algebraic = %q, want %q", got, "N@d4")
+	}
+
+	// in Chess960 the king may castle onto its own rook, which
+	// does not go to the pocket
+	game, err = encoder.FromVariantFEN(chess.Crazyhouse{}, "4k3/8/8/8/8/8/8/5KR1[] w G - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err = encoder.FromAlgebraic(game, "O-O")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	want = "4k3/8/8/8/8/8/8/5RK1[] b - - 1 1"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+
+	// captured pieces may be dropped back, so positions from
+	// before a capture may still be repeated
+	game, err = encoder.FromVariantFEN(chess.Crazyhouse{}, "4k3/5b2/8/8/2B5/8/8/4K3[] w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alg := range []string{"Bxf7", "Kxf7", "B@c4", "Ke8", "Kd1", "B@f7", "Kd2", "Kd8", "Ke1", "Ke8"} {
+		move, err := encoder.FromAlgebraic(game, alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := game.MakeMove(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if count := game.RepetitionCount(); count != 2 {
+		t.Errorf("repetition count = %d, want 2", count)
+	}
+}
+
+func TestAntichess(t *testing.T) {
+	game := &chess.Game{}
+	game.InitVariant(chess.Antichess{})
+	for i, want := range []uint64{20, 400, 8067, 153299} {
+		if got := game.Perft(i + 1); got != want {
+			t.Errorf("perft(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// the knight must capture the rook, which leaves white with no pieces
+	game, err := encoder.FromVariantFEN(chess.Antichess{}, "8/8/8/8/8/8/2n5/R7 b - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moves := game.LegalMoves()
+	if len(moves) != 1 {
+		t.Fatalf("legal moves = %v, want only Nxa1", moves)
+	}
+	if err := game.MakeMove(moves[0]); err != nil {
+		t.Fatal(err)
+	}
+	want := chess.CompletionState{Done: true, Winner: chess.White, Reason: chess.TerminationNoMoves}
+	if game.Completion != want {
+		t.Errorf("completion = %v, want %v", game.Completion, want)
+	}
+
+	// pawns may promote to kings
+	game, err = encoder.FromVariantFEN(chess.Antichess{}, "8/6P1/8/8/8/8/8/1n6 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err := encoder.FromAlgebraic(game, "g8K")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := game.PieceAt(chess.Space{File: 6, Rank: 7}); p.Type != chess.PieceKing {
+		t.Errorf("promoted to %v, want King", p.Type)
+	}
+}
+
+func TestHorde(t *testing.T) {
+	game := &chess.Game{}
+	game.InitVariant(chess.Horde{})
+	want := "rnbqkbnr/pppppppp/8/1PP2PP1/PPPPPPPP/PPPPPPPP/PPPPPPPP/PPPPPPPP w kq - 0 1"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+	for i, want := range []uint64{8, 128, 1274, 23310} {
+		if got := game.Perft(i + 1); got != want {
+			t.Errorf("perft(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// pawns on the first rank may move two spaces up,
+	// and black wins by capturing the last of them
+	game, err := encoder.FromVariantFEN(chess.Horde{}, "4k3/8/8/8/8/r7/8/4P3 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alg := range []string{"e3", "Rxe3"} {
+		move, err := encoder.FromAlgebraic(game, alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := game.MakeMove(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wantCompletion := chess.CompletionState{Done: true, Winner: chess.Black, Reason: chess.TerminationHordeCaptured}
+	if game.Completion != wantCompletion {
+		t.Errorf("completion = %v, want %v", game.Completion, wantCompletion)
+	}
+}
+
+func TestRacingKings(t *testing.T) {
+	game := &chess.Game{}
+	game.InitVariant(chess.RacingKings{})
+	want := "8/8/8/8/8/8/krbnNBRK/qrbnNBRQ w - - 0 1"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+	for i, want := range []uint64{21, 421, 11264} {
+		if got := game.Perft(i + 1); got != want {
+			t.Errorf("perft(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// black may reply to white's king reaching the eighth rank with a draw
+	game, err := encoder.FromVariantFEN(chess.RacingKings{}, "8/1k3K2/8/8/8/8/8/8 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err := encoder.FromAlgebraic(game, "Kf8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	if game.Completion.Done {
+		t.Fatalf("completion = %v, want black to reply", game.Completion)
+	}
+	move, err = encoder.FromAlgebraic(game, "Kb6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	wantCompletion := chess.CompletionState{Done: true, Winner: chess.White, Reason: chess.TerminationEighthRank}
+	if game.Completion != wantCompletion {
+		t.Errorf("completion = %v, want %v", game.Completion, wantCompletion)
+	}
+}
+
+func TestBughouse(t *testing.T) {
+	match := &chess.BughouseMatch{}
+	match.Init()
+	for _, alg := range []string{"e4", "d5", "exd5"} {
+		move, err := encoder.FromAlgebraic(match.Boards[0], alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := match.MakeMove(0, move); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the pawn captured by white on the first board goes to their partner
+	if match.Boards[1].Pockets.Black[chess.PiecePawn] != 1 || match.Boards[0].Pockets.White.Count() != 0 {
+		t.Errorf("pockets = %v and %v, want the pawn on the second board", match.Boards[0].Pockets, match.Boards[1].Pockets)
+	}
+
+	// white resigning on the second board loses the match for their team
+	if err := match.Resign(1, chess.White); err != nil {
+		t.Fatal(err)
+	}
+	want := chess.CompletionState{Done: true, Winner: chess.White, Reason: chess.TerminationResignation}
+	if match.Completion != want {
+		t.Errorf("completion = %v, want %v", match.Completion, want)
+	}
+}
+
+func TestCapablanca(t *testing.T) {
+	game := &chess.Game{}
+	game.InitVariant(chess.Capablanca{})
+	want := "rnabqkbcnr/pppppppppp/10/10/10/10/PPPPPPPPPP/RNABQKBCNR w KQkq - 0 1"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+	for i, want := range []uint64{28, 784, 25228} {
+		if got := game.Perft(i + 1); got != want {
+			t.Errorf("perft(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// the king castles to the i-file, next to the rook
+	game, err := encoder.FromVariantFEN(chess.Capablanca{}, "r4k3r/10/10/10/10/10/10/R4K3R w KQkq - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err := encoder.FromAlgebraic(game, "O-O")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	want = "r4k3r/10/10/10/10/10/10/R6RK1 b kq - 1 1"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+}
+
+func TestGrandChess(t *testing.T) {
+	game := &chess.Game{}
+	game.InitVariant(chess.GrandChess{})
+	want := "r8r/1nbqkcabn1/pppppppppp/10/10/10/10/PPPPPPPPPP/1NBQKCABN1/R8R w - - 0 1"
+	if got := readAll(t, encoder.FENReader(game)); got != want {
+		t.Errorf("FEN = %q, want %q", got, want)
+	}
+	for i, want := range []uint64{65, 4225} {
+		if got := game.Perft(i + 1); got != want {
+			t.Errorf("perft(%d) = %d, want %d", i+1, got, want)
+		}
+	}
+
+	// pawns may only promote to the pieces that white has lost, which
+	// are all but the queen, and only must promote on the last rank
+	game, err := encoder.FromVariantFEN(chess.GrandChess{}, "k9/8P1/7P2/10/10/10/10/10/10/1Q7K w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	promotions := map[int]int{}
+	for _, move := range game.LegalMoves() {
+		if move.Moving.Type == chess.PiecePawn {
+			promotions[move.To.Rank]++
+		}
+	}
+	if promotions[8] != 6 || promotions[9] != 5 {
+		t.Errorf("pawn moves to the ninth and tenth ranks = %d and %d, want 6 and 5", promotions[8], promotions[9])
+	}
+}
+
+func TestFourPlayer(t *testing.T) {
+	game := &chess.FourPlayerGame{}
+	game.Init()
+	if moves := len(game.LegalMoves()); moves != 20 {
+		t.Errorf("red has %d moves at the start, want 20", moves)
+	}
+
+	// blue's pawns move along the ranks
+	red := chess.FourPlayerMove{From: chess.Space{File: 7, Rank: 1}, To: chess.Space{File: 7, Rank: 3}}
+	blue := chess.FourPlayerMove{From: chess.Space{File: 1, Rank: 6}, To: chess.Space{File: 3, Rank: 6}}
+	for _, move := range []chess.FourPlayerMove{red, blue} {
+		if err := game.MakeMove(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if game.Turn() != chess.PlayerYellow {
+		t.Errorf("turn = %v, want Yellow", game.Turn())
+	}
+
+	// red checkmates blue's king on a4 with the queen, which is protected by the rook
+	game.InitCustom([]chess.FourPlayerPiece{
+		{Type: chess.PieceKing, Player: chess.PlayerRed, Location: chess.Space{File: 7, Rank: 0}},
+		{Type: chess.PieceQueen, Player: chess.PlayerRed, Location: chess.Space{File: 4, Rank: 4}},
+		{Type: chess.PieceRook, Player: chess.PlayerRed, Location: chess.Space{File: 1, Rank: 10}},
+		{Type: chess.PieceKing, Player: chess.PlayerBlue, Location: chess.Space{File: 0, Rank: 3}},
+		{Type: chess.PieceKing, Player: chess.PlayerYellow, Location: chess.Space{File: 6, Rank: 13}},
+		{Type: chess.PieceKing, Player: chess.PlayerGreen, Location: chess.Space{File: 13, Rank: 7}},
+	})
+	mate := chess.FourPlayerMove{From: chess.Space{File: 4, Rank: 4}, To: chess.Space{File: 1, Rank: 4}}
+	if err := game.MakeMove(mate); err != nil {
+		t.Fatal(err)
+	}
+	if !game.Eliminated[chess.PlayerBlue] || game.Scores[chess.PlayerRed] != 20 || game.Turn() != chess.PlayerYellow {
+		t.Errorf("eliminated = %v, scores = %v, turn = %v, want blue eliminated, 20 points for red and yellow to move",
+			game.Eliminated, game.Scores, game.Turn())
+	}
+
+	for _, p := range []chess.Player{chess.PlayerYellow, chess.PlayerGreen} {
+		if err := game.Resign(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := chess.FourPlayerCompletion{Done: true, Winner: chess.PlayerRed}
+	if game.Completion != want {
+		t.Errorf("completion = %v, want %v", game.Completion, want)
+	}
+}
+
+func TestKriegspiel(t *testing.T) {
+	k := &chess.Kriegspiel{}
+	k.Init()
+
+	space := func(s string) chess.Space {
+		return chess.Space{File: int(s[0] - 'a'), Rank: int(s[1] - '1')}
+	}
+	attempt := func(from, to string) chess.KriegspielAnnouncement {
+		announcement, err := k.Attempt(space(from), space(to), chess.PieceNone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return announcement
+	}
+
+	for _, move := range [][2]string{{"e2", "e4"}, {"d7", "d5"}} {
+		attempt(move[0], move[1])
+	}
+	if got := attempt("d1", "h5"); got.Illegal || got.PawnCaptures != 1 {
+		t.Errorf("Qh5 = %v, want 1 pawn capture", got)
+	}
+	// the pawn on f7 is pinned by the queen
+	if got := attempt("f7", "f6"); !got.Illegal {
+		t.Errorf("f6 = %v, want illegal", got)
+	}
+	attempt("e7", "e6")
+	got := attempt("h5", "f7")
+	if !got.Capture || got.CaptureAt != space("f7") || len(got.Checks) != 1 || got.Checks[0] != chess.CheckShortDiagonal {
+		t.Errorf("Qxf7+ = %v, want capture on f7, check on the short diagonal", got)
+	}
+
+	for _, p := range k.View(chess.Black).Pieces {
+		if p.Color != chess.Black {
+			t.Errorf("black's view has %v", p)
+		}
+	}
+}
+
+func TestDuckChess(t *testing.T) {
+	game := &chess.Game{}
+	game.InitVariant(chess.Duck{})
+	if moves := len(game.LegalMoves()); moves != 640 {
+		t.Errorf("white has %d moves at the start, want 640", moves)
+	}
+	if divided := len(game.Divide(1)); divided != 640 {
+		t.Errorf("divide has %d moves at the start, want 640", divided)
+	}
+
+	// moves which do not say where the duck goes are not legal
+	move := game.LegalMoves()[0]
+	move.HasDuck = false
+	if err := game.MakeMove(move); err == nil {
+		t.Errorf("%v is legal without moving the duck", move)
+	}
+
+	// the duck blocks the rook, and must move to a different space
+	game, err := encoder.FromVariantFEN(chess.Duck{}, "4k3/8/8/8/4*3/8/8/4R1K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, alg := range []string{"Rxe8,a5", "Re3,e4", "Re3"} {
+		if _, err := encoder.FromAlgebraic(game, alg); err == nil {
+			t.Errorf("%s is legal", alg)
+		}
+	}
+	for _, alg := range []string{"Re3,d4", "Kd7,d5", "Re8,f8", "Kxe8,g8"} {
+		move, err := encoder.FromAlgebraic(game, alg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := game.MakeMove(move); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fen := readAll(t, encoder.FENReader(game)); fen != "4k1*1/8/8/8/8/8/8/6K1 w - - 0 3" {
+		t.Errorf("fen = %s", fen)
+	}
+	if material := game.InsufficientMaterial(); material != chess.MaterialKingVsKing {
+		t.Errorf("material = %v, want the duck to not be counted", material)
+	}
+
+	// there is no check, so kings may be captured
+	game, err = encoder.FromVariantFEN(chess.Duck{}, "4k3/8/8/8/3*4/8/8/4R1K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	move, err = encoder.FromAlgebraic(game, "Rxe8,a5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := game.MakeMove(move); err != nil {
+		t.Fatal(err)
+	}
+	want := chess.CompletionState{Done: true, Winner: chess.White, Reason: chess.TerminationKingCaptured}
+	if game.Completion != want {
+		t.Errorf("completion = %v, want %v", game.Completion, want)
+	}
+}
@@ -0,0 +1,191 @@
+package chess
+
+// random keys for zobrist hashing. they are generated from a fixed
+// seed so that hashes are the same between runs of a program.
+var (
+	// indexed by [colorIndex][PieceType][square]
+	zobristPieces [2][pieceTypes][maxSquares]uint64
+
+	zobristBlackToMove uint64
+
+	// indexed by WhiteKing, WhiteQueen, BlackKing, BlackQueen
+	zobristCastles [4]uint64
+
+	// indexed by the file of the en passant target
+	zobristEnPassant [maxFiles]uint64
+
+	// indexed by [colorIndex][checks given - 1], up to three checks
+	zobristChecks [2][3]uint64
+
+	// indexed by [colorIndex][PieceType][number in pocket - 1]
+	zobristPockets [2][pieceTypes][16]uint64
+
+	// multiplied by the bitboard of promoted pieces
+	zobristPromoted uint64
+
+	// indexed by the square of the duck
+	zobristDuck [64]uint64
+)
+
+func init() {
+	// xorshift64*
+	state := uint64(0x9E3779B97F4A7C15)
+	next := func() uint64 {
+		state ^= state >> 12
+		state ^= state << 25
+		state ^= state >> 27
+		return state * 0x2545F4914F6CDD1D
+	}
+
+	for c := range zobristPieces {
+		for t := range zobristPieces[c] {
+			for sq := range zobristPieces[c][t] {
+				zobristPieces[c][t][sq] = next()
+			}
+		}
+	}
+	zobristBlackToMove = next()
+	for i := range zobristCastles {
+		zobristCastles[i] = next()
+	}
+	for i := range zobristEnPassant {
+		zobristEnPassant[i] = next()
+	}
+	for c := range zobristChecks {
+		for i := range zobristChecks[c] {
+			zobristChecks[c][i] = next()
+		}
+	}
+	for c := range zobristPockets {
+		for t := range zobristPockets[c] {
+			for i := range zobristPockets[c][t] {
+				zobristPockets[c][t][i] = next()
+			}
+		}
+	}
+	zobristPromoted = next() | 1
+	for sq := range zobristDuck {
+		zobristDuck[sq] = next()
+	}
+}
+
+// PositionKey identifies a position. Two positions have equal keys if
+// they have the same pieces on the same spaces, the same player to move,
+// the same castling rights, the same en passant capture available, the
+// same number of checks given, the same pieces in the pockets and
+// promoted, and the duck on the same space. An en passant target only
+// counts if the capture is actually possible.
+//
+// PositionKey is comparable, so it may be used as a map key.
+type PositionKey struct {
+	colors    [2]Bitboard
+	types     [pieceTypes]Bitboard
+	mailbox   [maxSquares]PieceType
+	whites    squareSet
+	turn      Color
+	castles   castlingRights
+	enPassant Space
+	checks    checkCounts
+	pockets   pockets
+	promoted  Bitboard
+	duck      Bitboard
+}
+
+// PositionKey returns the key for g's current position.
+func (g *Game) PositionKey() PositionKey {
+	key := PositionKey{
+		colors:   g.colors,
+		types:    g.types,
+		mailbox:  g.mailbox,
+		whites:   g.whites,
+		turn:     g.Turn(),
+		castles:  g.Castles,
+		checks:   g.Checks,
+		pockets:  g.Pockets,
+		promoted: g.Promoted,
+		duck:     g.Duck,
+	}
+	if g.enPassantCapturable() {
+		key.enPassant = g.EnPassant
+	}
+	return key
+}
+
+// Hash returns the zobrist hash of g's current position. The hash
+// covers the same information as PositionKey, so equal keys always
+// have equal hashes, however different keys may collide.
+func (g *Game) Hash() uint64 {
+	// the pieces are hashed incrementally as they are put and removed,
+	// the rest are exported fields and are hashed as they are needed
+	hash := g.hash
+
+	if g.Turn() == Black {
+		hash ^= zobristBlackToMove
+	}
+	for i, right := range [...]bool{
+		g.Castles.WhiteKing,
+		g.Castles.WhiteQueen,
+		g.Castles.BlackKing,
+		g.Castles.BlackQueen,
+	} {
+		if right {
+			hash ^= zobristCastles[i]
+		}
+	}
+	if g.enPassantCapturable() {
+		hash ^= zobristEnPassant[g.EnPassant.File]
+	}
+	for i, checks := range [...]int{g.Checks.Black, g.Checks.White} {
+		if checks > 3 {
+			checks = 3
+		}
+		if checks > 0 {
+			hash ^= zobristChecks[i][checks-1]
+		}
+	}
+	for i, pocket := range [...]Pocket{g.Pockets.Black, g.Pockets.White} {
+		for t, n := range pocket {
+			if n > 16 {
+				n = 16
+			}
+			if n > 0 {
+				hash ^= zobristPockets[i][t][n-1]
+			}
+		}
+	}
+	hash ^= uint64(g.Promoted) * zobristPromoted
+	if g.Duck != 0 {
+		hash ^= zobristDuck[g.Duck.lowest()]
+	}
+
+	return hash
+}
+
+// enPassantCapturable returns if the player to move
+// is able to legally capture en passant.
+func (g *Game) enPassantCapturable() bool {
+	if !g.hasEnPassant() {
+		return false
+	}
+
+	c := g.Turn()
+	back := -1
+	if c == Black {
+		back = 1
+	}
+	for _, file := range [...]int{g.EnPassant.File - 1, g.EnPassant.File + 1} {
+		from := Space{File: file, Rank: g.EnPassant.Rank + back}
+		if !g.OnBoard(from) || g.mailbox[g.square(from)] != PiecePawn || g.colorAt(g.square(from)) != c {
+			continue
+		}
+
+		u := g.makeMove(move{from: g.square(from), to: g.square(g.EnPassant), enPassant: true})
+		inCheck := g.InCheck(c)
+		g.unmakeMove(u)
+		if !inCheck {
+			return true
+		}
+	}
+
+	return false
+}